@@ -0,0 +1,264 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ContentLayout is how the files of a torrent are laid out in the save path.
+type ContentLayout string
+
+const (
+	ContentLayoutOriginal    ContentLayout = "Original"    // as in the torrent
+	ContentLayoutSubfolder   ContentLayout = "Subfolder"   // always in a folder named after the torrent
+	ContentLayoutNoSubfolder ContentLayout = "NoSubfolder" // without the root folder of the torrent
+)
+
+// StopCondition is when a torrent added with AddTorrentOptions is stopped.
+type StopCondition string
+
+const (
+	StopConditionNone             StopCondition = "None"
+	StopConditionMetadataReceived StopCondition = "MetadataReceived"
+	StopConditionFilesChecked     StopCondition = "FilesChecked"
+)
+
+// AddTorrentOptions holds the parameters of `torrents/add`.
+// The zero value of a field leaves the server default.
+type AddTorrentOptions struct {
+	// SavePath is the directory of the torrent on the server.
+	SavePath string
+	// DownloadPath is the directory of the incomplete torrent, qBittorrent v4.4.0+.
+	DownloadPath string
+	Category     string
+	Tags         []string
+	// Rename is the name of the torrent.
+	Rename       string
+	SkipChecking bool
+	// Stopped adds the torrent stopped (paused).
+	Stopped bool
+	// ContentLayout is sent as `root_folder` to servers older than qBittorrent v4.3.2.
+	ContentLayout ContentLayout
+	// StopCondition stops the torrent once reached, qBittorrent v4.5.0+.
+	StopCondition StopCondition
+	// UpLimit and DLLimit are the speed limits of the torrent.
+	UpLimit SpeedLimit
+	DLLimit SpeedLimit
+	// RatioLimit, SeedingTimeLimit and InactiveSeedingTimeLimit are share limits,
+	// see ShareLimits.
+	RatioLimit               RatioLimit
+	SeedingTimeLimit         TimeLimit
+	InactiveSeedingTimeLimit TimeLimit
+	// AutoTMM enables or disables the automatic torrent management,
+	// nil leaves the server default.
+	AutoTMM            *bool
+	SequentialDownload bool
+	FirstLastPiecePrio bool
+}
+
+// encode returns the fields of o for a server with the web API v.
+func (o *AddTorrentOptions) encode(v apiVersion) Optional {
+	opt := Optional{}
+	if o == nil {
+		return opt
+	}
+	setStr := func(k, s string) {
+		if s != "" {
+			opt[k] = s
+		}
+	}
+	setTrue := func(k string, b bool) {
+		if b {
+			opt[k] = true
+		}
+	}
+	setInt := func(k string, n int) {
+		if n != 0 {
+			opt[k] = n
+		}
+	}
+	setStr("savepath", o.SavePath)
+	if o.DownloadPath != "" {
+		opt["useDownloadPath"] = true
+		opt["downloadPath"] = o.DownloadPath
+	}
+	setStr("category", o.Category)
+	setStr("tags", strings.Join(o.Tags, ","))
+	setStr("rename", o.Rename)
+	setTrue("skip_checking", o.SkipChecking)
+	if v.atLeast(apiStopStart) {
+		setTrue("stopped", o.Stopped)
+	} else {
+		setTrue("paused", o.Stopped)
+	}
+	if v.atLeast(apiContentLayout) {
+		setStr("contentLayout", string(o.ContentLayout))
+	} else {
+		switch o.ContentLayout {
+		case ContentLayoutSubfolder:
+			opt["root_folder"] = true
+		case ContentLayoutNoSubfolder:
+			opt["root_folder"] = false
+		}
+	}
+	setStr("stopCondition", string(o.StopCondition))
+	setInt("upLimit", int(o.UpLimit))
+	setInt("dlLimit", int(o.DLLimit))
+	if o.RatioLimit != 0 {
+		opt["ratioLimit"] = float64(o.RatioLimit)
+	}
+	setInt("seedingTimeLimit", int(o.SeedingTimeLimit))
+	setInt("inactiveSeedingTimeLimit", int(o.InactiveSeedingTimeLimit))
+	if o.AutoTMM != nil {
+		opt["autoTMM"] = *o.AutoTMM
+	}
+	setTrue("sequentialDownload", o.SequentialDownload)
+	setTrue("firstLastPiecePrio", o.FirstLastPiecePrio)
+	return opt
+}
+
+// AddTorrentURLs adds torrents from urls, http(s) links to torrent files
+// or magnet links, with opts which may be nil.
+// It fails with ErrAddTorrnetfailed if the server adds none of them.
+func (c *Client) AddTorrentURLs(urls []string, opts *AddTorrentOptions) error {
+	return c.AddTorrentURLsContext(context.Background(), urls, opts)
+}
+
+// AddTorrentURLsContext is like AddTorrentURLs but uses ctx for the requests.
+func (c *Client) AddTorrentURLsContext(ctx context.Context, urls []string, opts *AddTorrentOptions) error {
+	return c.AddTorrentsContext(ctx, urls, nil, opts)
+}
+
+// AddTorrents adds torrents from urls and .torrent files in a single request,
+// with opts which may be nil. The files are streamed to the server.
+// It fails with ErrAddTorrnetfailed if the server adds none of them.
+func (c *Client) AddTorrents(urls []string, files []TorrentUpload, opts *AddTorrentOptions) error {
+	return c.AddTorrentsContext(context.Background(), urls, files, opts)
+}
+
+// AddTorrentsContext is like AddTorrents but uses ctx for the requests.
+func (c *Client) AddTorrentsContext(ctx context.Context, urls []string, files []TorrentUpload, opts *AddTorrentOptions) error {
+	if len(urls) == 0 && len(files) == 0 {
+		return errors.New("no torrent to add")
+	}
+	v, err := c.apiVersion(ctx)
+	if err != nil {
+		return err
+	}
+	opt := opts.encode(v)
+	if len(urls) > 0 {
+		opt["urls"] = strings.Join(urls, "\n")
+	}
+	resp, err := c.postMultipartStream(ctx, "torrents/add", opt, files...)
+	err = RespOk(resp, err)
+	if err != nil {
+		return err
+	}
+	return RespBodyOk(resp.Body, ErrAddTorrnetfailed)
+}
+
+// addPollInterval is how often AddTorrentFileAndWait and AddMagnetAndWait
+// look for the added torrent.
+const addPollInterval = 250 * time.Millisecond
+
+// AddTorrentFileAndWait adds a .torrent file and waits until the server lists it.
+// If the server already has the torrent it returns it with an error matching
+// ErrTorrentExists. It waits without a timeout, see AddTorrentFileAndWaitContext.
+func (c *Client) AddTorrentFileAndWait(f TorrentUpload, opts *AddTorrentOptions) (Torrent, error) {
+	return c.AddTorrentFileAndWaitContext(context.Background(), f, opts)
+}
+
+// AddTorrentFileAndWaitContext is like AddTorrentFileAndWait but uses ctx for the requests,
+// it stops waiting when ctx is done.
+func (c *Client) AddTorrentFileAndWaitContext(ctx context.Context, f TorrentUpload, opts *AddTorrentOptions) (Torrent, error) {
+	r, _, closeFile, err := f.open()
+	if err != nil {
+		return Torrent{}, err
+	}
+	b, err := io.ReadAll(r)
+	closeFile()
+	if err != nil {
+		return Torrent{}, err
+	}
+	h, err := TorrentInfoHash(b)
+	if err != nil {
+		return Torrent{}, err
+	}
+	return c.addAndWait(ctx, h, func() error {
+		return c.AddTorrentsContext(ctx, nil, []TorrentUpload{UploadBytes(f.Name, b)}, opts)
+	})
+}
+
+// AddMagnetAndWait adds a magnet link and waits until the server lists it.
+// If the server already has the torrent it returns it with an error matching
+// ErrTorrentExists. It waits without a timeout, see AddMagnetAndWaitContext.
+func (c *Client) AddMagnetAndWait(magnet string, opts *AddTorrentOptions) (Torrent, error) {
+	return c.AddMagnetAndWaitContext(context.Background(), magnet, opts)
+}
+
+// AddMagnetAndWaitContext is like AddMagnetAndWait but uses ctx for the requests,
+// it stops waiting when ctx is done.
+func (c *Client) AddMagnetAndWaitContext(ctx context.Context, magnet string, opts *AddTorrentOptions) (Torrent, error) {
+	h, err := MagnetInfoHash(magnet)
+	if err != nil {
+		return Torrent{}, err
+	}
+	return c.addAndWait(ctx, h, func() error {
+		return c.AddTorrentsContext(ctx, []string{magnet}, nil, opts)
+	})
+}
+
+// addAndWait adds the torrent with the info-hashes h by calling add,
+// then polls `torrents/info` until it is listed.
+func (c *Client) addAndWait(ctx context.Context, h InfoHash, add func() error) (Torrent, error) {
+	id := h.ID()
+	t, ok, err := c.findTorrent(ctx, id)
+	if err != nil {
+		return Torrent{}, err
+	}
+	if ok {
+		return t, fmt.Errorf("%w: %s", ErrTorrentExists, id)
+	}
+	if err = add(); err != nil {
+		// the server refuses duplicates with "Fails.", or 409 Conflict since qBittorrent v5.1.0,
+		// the torrent may also have been added meanwhile
+		if !errors.Is(err, ErrAddTorrnetfailed) && !errors.Is(err, ErrConflict) {
+			return Torrent{}, err
+		}
+		t, ok, ferr := c.findTorrent(ctx, id)
+		if ferr != nil || !ok {
+			return Torrent{}, err
+		}
+		return t, fmt.Errorf("%w: %s", ErrTorrentExists, id)
+	}
+	for {
+		t, ok, err := c.findTorrent(ctx, id)
+		if err != nil {
+			return Torrent{}, err
+		}
+		if ok {
+			return t, nil
+		}
+		if !sleep(ctx, addPollInterval) {
+			return Torrent{}, ctx.Err()
+		}
+	}
+}
+
+// findTorrent looks up a torrent by hash in `torrents/info`.
+func (c *Client) findTorrent(ctx context.Context, hash string) (Torrent, bool, error) {
+	ts, err := c.TorrentListContext(ctx, Optional{"hashes": hash})
+	if err != nil {
+		return Torrent{}, false, err
+	}
+	for _, t := range ts {
+		if strings.EqualFold(t.Hash, hash) {
+			return t, true, nil
+		}
+	}
+	return Torrent{}, false, nil
+}
@@ -0,0 +1,234 @@
+package qbt_apiv2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NullpointerW/go-qbittorrent-apiv2/metainfo"
+)
+
+func TestAddTorrentOptionsEncode(t *testing.T) {
+	autoTMM := false
+	o := &AddTorrentOptions{
+		SavePath:      "/data",
+		Tags:          []string{"linux", "iso"},
+		Stopped:       true,
+		ContentLayout: ContentLayoutNoSubfolder,
+		DLLimit:       1024,
+		RatioLimit:    RatioUnlimited,
+		AutoTMM:       &autoTMM,
+	}
+	for _, tt := range []struct {
+		v    apiVersion
+		want string
+	}{
+		{apiVersion{2, 2, 0}, "map[autoTMM:false dlLimit:1024 paused:true ratioLimit:-1 root_folder:false savepath:/data tags:linux,iso]"},
+		{apiVersion{2, 8, 3}, "map[autoTMM:false contentLayout:NoSubfolder dlLimit:1024 paused:true ratioLimit:-1 savepath:/data tags:linux,iso]"},
+		{apiVersion{2, 11, 2}, "map[autoTMM:false contentLayout:NoSubfolder dlLimit:1024 ratioLimit:-1 savepath:/data stopped:true tags:linux,iso]"},
+	} {
+		if got := fmt.Sprint(o.encode(tt.v).StringField()); got != tt.want {
+			t.Errorf("%s: encode = %s, want %s", tt.v, got, tt.want)
+		}
+	}
+	if got := (*AddTorrentOptions)(nil).encode(apiVersion{2, 11, 0}); len(got) != 0 {
+		t.Errorf("nil options encode to %v", got)
+	}
+	large := (&AddTorrentOptions{
+		UpLimit:          10 << 20,
+		DLLimit:          1000000,
+		SeedingTimeLimit: 10000000,
+	}).encode(apiVersion{2, 11, 0}).StringField()
+	if large["upLimit"] != "10485760" || large["dlLimit"] != "1000000" || large["seedingTimeLimit"] != "10000000" {
+		t.Errorf("large limits encode to %v", large)
+	}
+}
+
+func TestAddTorrentURLs(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("app/webapiVersion", "2.11.0")
+	var form url.Values
+	srv.handle("torrents/add", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		form = r.MultipartForm.Value
+		w.Write([]byte("Ok."))
+	})
+	cli := srv.client(t)
+	err := cli.AddTorrentURLs([]string{"magnet:?xt=urn:btih:aaa", "https://example.com/b.torrent"},
+		&AddTorrentOptions{Category: "iso", StopCondition: StopConditionMetadataReceived})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("urls") != "magnet:?xt=urn:btih:aaa\nhttps://example.com/b.torrent" ||
+		form.Get("category") != "iso" || form.Get("stopCondition") != "MetadataReceived" {
+		t.Errorf("torrents/add form = %v", form)
+	}
+}
+
+func TestAddTorrentsStreamed(t *testing.T) {
+	srv := newFakeServer(t)
+	ss := new(sessions)
+	srv.handle("auth/login", ss.login)
+	srv.handle("app/webapiVersion", ss.guard(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("2.11.0"))
+	}))
+	var (
+		files []string
+		form  url.Values
+	)
+	srv.handle("torrents/add", ss.guard(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength <= 0 || len(r.TransferEncoding) != 0 {
+			t.Errorf("body sent with length %d, encoding %v", r.ContentLength, r.TransferEncoding)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		form = r.MultipartForm.Value
+		files = nil
+		for _, fh := range r.MultipartForm.File["torrents"] {
+			f, _ := fh.Open()
+			b, _ := io.ReadAll(f)
+			f.Close()
+			files = append(files, fh.Filename+"="+string(b))
+		}
+		w.Write([]byte(ResponseBodyOK))
+	}))
+	cli := srv.client(t, WithAuth("admin", "adminadmin"))
+
+	fn := filepath.Join(t.TempDir(), "a.torrent")
+	if err := os.WriteFile(fn, []byte("d4:infod1:ai1eee"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	seeker := bytes.NewReader([]byte("skipd4:infod1:ci3eee"))
+	seeker.Seek(4, io.SeekStart)
+	uploads := []TorrentUpload{
+		UploadFile(fn),
+		UploadBytes("b.torrent", []byte("d4:infod1:bi2eee")),
+		UploadReader("c.torrent", seeker),
+		// not seekable, must be buffered to be replayed
+		UploadReader("d.torrent", io.MultiReader(strings.NewReader("d4:infod1:di4eee"))),
+	}
+	// cache the version, so the session expires on torrents/add
+	if _, err := cli.apiVersion(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ss.expire()
+	err := cli.AddTorrents([]string{"magnet:?xt=urn:btih:eee"}, uploads, &AddTorrentOptions{Tags: []string{"x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.called("torrents/add"); n != 2 {
+		t.Errorf("torrents/add called %d times, want a replay after login", n)
+	}
+	sort.Strings(files)
+	want := []string{
+		"a.torrent=d4:infod1:ai1eee", "b.torrent=d4:infod1:bi2eee",
+		"c.torrent=d4:infod1:ci3eee", "d.torrent=d4:infod1:di4eee",
+	}
+	if fmt.Sprint(files) != fmt.Sprint(want) {
+		t.Errorf("files = %q", files)
+	}
+	if form.Get("urls") != "magnet:?xt=urn:btih:eee" || form.Get("tags") != "x" {
+		t.Errorf("form = %v", form)
+	}
+}
+
+func TestAddMagnetAndWait(t *testing.T) {
+	const hash = "c9e15763f722f23e98a29decdfae341b98d53056"
+	srv := newFakeServer(t)
+	srv.reply("app/webapiVersion", "2.11.0")
+	var added bool
+	polls := 0
+	srv.handle("torrents/info", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("hashes") != hash {
+			t.Errorf("torrents/info hashes = %q", r.FormValue("hashes"))
+		}
+		polls++
+		if !added || polls < 4 {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"hash":"` + hash + `","name":"x","state":"metaDL"}]`))
+	})
+	srv.handle("torrents/add", func(w http.ResponseWriter, r *http.Request) {
+		if added {
+			w.Write([]byte(ResponseBodyFAIL))
+			return
+		}
+		added = true
+		w.Write([]byte(ResponseBodyOK))
+	})
+	cli := srv.client(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	magnet := "magnet:?xt=urn:btih:" + strings.ToUpper(hash)
+	tr, err := cli.AddMagnetAndWaitContext(ctx, magnet, nil)
+	if err != nil || tr.Hash != hash {
+		t.Fatalf("AddMagnetAndWait = %+v, %v", tr, err)
+	}
+	tr, err = cli.AddMagnetAndWaitContext(ctx, magnet, nil)
+	if !errors.Is(err, ErrTorrentExists) || tr.Name != "x" || srv.called("torrents/add") != 1 {
+		t.Errorf("adding twice = %+v, %v", tr, err)
+	}
+}
+
+// failOnce fails the first read, then returns the rest of its content.
+type failOnce struct {
+	failed bool
+	r      io.Reader
+}
+
+func (f *failOnce) Read(p []byte) (int, error) {
+	if !f.failed {
+		f.failed = true
+		return 0, errors.New("read failed")
+	}
+	return f.r.Read(p)
+}
+
+func TestUploadReaderOnce(t *testing.T) {
+	// MultiReader hides the io.Seeker of strings.Reader
+	up := UploadReader("a.torrent", io.MultiReader(strings.NewReader("torrent")))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, n, _, err := up.open()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if b, _ := io.ReadAll(r); string(b) != "torrent" || n != 7 {
+				t.Errorf("open = %q, %d", b, n)
+			}
+		}()
+	}
+	wg.Wait()
+
+	up = UploadReader("b.torrent", &failOnce{r: strings.NewReader("rest")})
+	for i := 0; i < 2; i++ {
+		if _, _, _, err := up.open(); err == nil || err.Error() != "read failed" {
+			t.Errorf("open %d = %v, want the first read error", i, err)
+		}
+	}
+}
+
+func TestUploadMetaInfoBuiltByHand(t *testing.T) {
+	// not parsed, and without the pieces of a v1 torrent
+	mi := &metainfo.MetaInfo{Info: metainfo.Info{Name: "x", PieceLength: 16384}}
+	if _, _, _, err := UploadMetaInfo(mi).open(); !errors.Is(err, metainfo.ErrInvalid) {
+		t.Errorf("open = %v, want metainfo.ErrInvalid", err)
+	}
+}
@@ -1,6 +1,7 @@
 package qbt_apiv2
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 )
@@ -261,12 +262,17 @@ func (c *Config) MarshalJSON() ([]byte, error) {
 }
 
 func (c *Client) GetPreferences() (cfg Config, err error) {
-	vI, err := c.getVersion()
+	return c.GetPreferencesContext(context.Background())
+}
+
+// GetPreferencesContext is like GetPreferences but uses ctx for the request.
+func (c *Client) GetPreferencesContext(ctx context.Context) (cfg Config, err error) {
+	vI, err := c.getVersion(ctx)
 	if err != nil {
 		return cfg, err
 	}
 	cfg.version = vI
-	resp, err := c.postXwwwFormUrlencoded("app/preferences", nil)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "app/preferences", nil)
 	err = RespOk(resp, err)
 	if err != nil {
 		return cfg, err
@@ -280,7 +286,12 @@ func (c *Client) GetPreferences() (cfg Config, err error) {
 	return cfg, err
 }
 func (c *Client) SetPreferences(cfg Config) (err error) {
-	vI, err := c.getVersion()
+	return c.SetPreferencesContext(context.Background(), cfg)
+}
+
+// SetPreferencesContext is like SetPreferences but uses ctx for the request.
+func (c *Client) SetPreferencesContext(ctx context.Context, cfg Config) (err error) {
+	vI, err := c.getVersion(ctx)
 	if err != nil {
 		return err
 	}
@@ -292,7 +303,7 @@ func (c *Client) SetPreferences(cfg Config) (err error) {
 	opt := Optional{
 		"json": string(b),
 	}
-	resp, err := c.postXwwwFormUrlencoded("app/setPreferences", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "app/setPreferences", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -300,8 +311,8 @@ func (c *Client) SetPreferences(cfg Config) (err error) {
 	ignrBody(resp.Body)
 	return nil
 }
-func (c *Client) getVersion() (version int, err error) {
-	v, err := c.GetVersion()
+func (c *Client) getVersion(ctx context.Context) (version int, err error) {
+	v, err := c.GetVersionContext(ctx)
 	if err != nil {
 		return -1, err
 	}
@@ -309,7 +320,12 @@ func (c *Client) getVersion() (version int, err error) {
 }
 
 func (c *Client) GetVersion() (ver string, err error) {
-	resp, err := c.postXwwwFormUrlencoded("app/version", nil)
+	return c.GetVersionContext(context.Background())
+}
+
+// GetVersionContext is like GetVersion but uses ctx for the request.
+func (c *Client) GetVersionContext(ctx context.Context) (ver string, err error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "app/version", nil)
 	err = RespOk(resp, err)
 	if err != nil {
 		return "", err
@@ -322,7 +338,12 @@ func (c *Client) GetVersion() (ver string, err error) {
 }
 
 func (c *Client) GetApiVersion() (ver string, err error) {
-	resp, err := c.postXwwwFormUrlencoded("app/webapiVersion", nil)
+	return c.GetApiVersionContext(context.Background())
+}
+
+// GetApiVersionContext is like GetApiVersion but uses ctx for the request.
+func (c *Client) GetApiVersionContext(ctx context.Context) (ver string, err error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "app/webapiVersion", nil)
 	err = RespOk(resp, err)
 	if err != nil {
 		return "", err
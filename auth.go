@@ -1,17 +1,49 @@
 package qbt_apiv2
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 )
 
-// Login
+// Login logs in and remembers the credentials,
+// they are used to login again when the session expires.
 func (c *Client) Login(username, password string) error {
+	return c.LoginContext(context.Background(), username, password)
+}
+
+// LoginContext is like Login but uses ctx for the request.
+func (c *Client) LoginContext(ctx context.Context, username, password string) error {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	return c.login(ctx, username, password)
+}
+
+// relogin logs in again with the remembered credentials unless another
+// goroutine has already done so since session was observed.
+// It reports whether the request may be replayed.
+func (c *Client) relogin(ctx context.Context, session uint64) (bool, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	if c.session != session {
+		return true, nil
+	}
+	if !c.reauth {
+		return false, nil
+	}
+	return true, c.login(ctx, c.username, c.password)
+}
+
+// login must be called with authMu held.
+func (c *Client) login(ctx context.Context, username, password string) error {
 	opts := Optional{
 		"username": username,
 		"password": password,
 	}
-	resp, err := c.postXwwwFormUrlencoded("auth/login", opts)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "auth/login", opts)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -28,5 +60,87 @@ func (c *Client) Login(username, password string) error {
 		u.Path = ""
 		c.httpCli.Jar.SetCookies(u, cookies)
 	}
+	c.username, c.password, c.reauth = username, password, true
+	c.session++
+	// the server may have been upgraded while the session was lost
+	c.verMu.Lock()
+	c.apiVer = nil
+	c.verMu.Unlock()
+	return nil
+}
+
+// Logout ends the session and forgets the credentials,
+// so the Client no longer re-authenticates until the next Login.
+func (c *Client) Logout() error {
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext is like Logout but uses ctx for the request.
+func (c *Client) LogoutContext(ctx context.Context) error {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "auth/logout", nil)
+	err = RespOk(resp, err)
+	if err != nil {
+		return err
+	}
+	ignrBody(resp.Body)
+	c.authMu.Lock()
+	c.username, c.password, c.reauth = "", "", false
+	c.authMu.Unlock()
 	return nil
 }
+
+// IsAuthenticated probes whether the session of the Client is still valid.
+// It never re-authenticates.
+func (c *Client) IsAuthenticated() (bool, error) {
+	return c.IsAuthenticatedContext(context.Background())
+}
+
+// IsAuthenticatedContext is like IsAuthenticated but uses ctx for the request.
+func (c *Client) IsAuthenticatedContext(ctx context.Context) (bool, error) {
+	if c.closed.Load() {
+		return false, ErrClientClosed
+	}
+	resp, err := c.send(ctx, "app/webapiVersion", func() (io.Reader, string, error) {
+		return http.NoBody, "application/x-www-form-urlencoded", nil
+	})
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		ignrBody(resp.Body)
+		resp.Body.Close()
+		return true, nil
+	case http.StatusForbidden:
+		apiErr := newAPIError(resp, "app/webapiVersion", nil)
+		if errors.Is(apiErr, ErrIPBanned) {
+			return false, apiErr
+		}
+		return false, nil
+	default:
+		return false, newAPIError(resp, "app/webapiVersion", nil)
+	}
+}
+
+// Close logs out and releases the idle connections of the underlying http.Client.
+// Any later request returns ErrClientClosed.
+func (c *Client) Close() error {
+	return c.CloseContext(context.Background())
+}
+
+// CloseContext is like Close but uses ctx for the request.
+func (c *Client) CloseContext(ctx context.Context) error {
+	if c.closed.Load() {
+		return nil
+	}
+	err := c.LogoutContext(ctx)
+	if errors.Is(err, ErrForbidden) {
+		// the session has already expired
+		err = nil
+	}
+	if !c.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	c.httpCli.CloseIdleConnections()
+	return err
+}
@@ -0,0 +1,167 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// sessions emulates the SID handling of the WebUI, expire invalidates every issued SID.
+type sessions struct {
+	mu    sync.Mutex
+	n     int
+	valid map[string]bool
+}
+
+func (s *sessions) login(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.n++
+	sid := "sid" + strconv.Itoa(s.n)
+	if s.valid == nil {
+		s.valid = make(map[string]bool)
+	}
+	s.valid[sid] = true
+	s.mu.Unlock()
+	http.SetCookie(w, &http.Cookie{Name: "SID", Value: sid, Path: "/"})
+	w.Write([]byte(ResponseBodyOK))
+}
+
+func (s *sessions) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.valid = nil
+}
+
+func (s *sessions) guard(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ck, err := r.Cookie("SID")
+		s.mu.Lock()
+		ok := err == nil && s.valid[ck.Value]
+		s.mu.Unlock()
+		if !ok {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func TestReauthOnExpiredSession(t *testing.T) {
+	srv := newFakeServer(t)
+	ss := new(sessions)
+	srv.handle("auth/login", ss.login)
+	srv.handle("app/version", ss.guard(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v4.6.2"))
+	}))
+	var uploads []string
+	srv.handle("torrents/add", ss.guard(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("torrents")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		b, _ := io.ReadAll(f)
+		uploads = append(uploads, string(b)+"|"+r.FormValue("category"))
+		w.Write([]byte(ResponseBodyOK))
+	}))
+	cli := srv.client(t, WithAuth("admin", "adminadmin"))
+
+	ss.expire()
+	if _, err := cli.GetVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.called("auth/login"); n != 2 {
+		t.Errorf("login called %d times, want 2", n)
+	}
+
+	fn := filepath.Join(t.TempDir(), "a.torrent")
+	if err := os.WriteFile(fn, []byte("d4:infod0:ee"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ss.expire()
+	resp, err := cli.postMultipartFile(context.Background(), "torrents/add", fn, Optional{"category": "movies"})
+	if err = RespOk(resp, err); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if len(uploads) != 1 || uploads[0] != "d4:infod0:ee|movies" {
+		t.Errorf("uploads = %q", uploads)
+	}
+}
+
+func TestReauthFailure(t *testing.T) {
+	srv := newFakeServer(t)
+	ss := new(sessions)
+	srv.handle("auth/login", ss.login)
+	srv.handle("app/version", ss.guard(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("v4.6.2"))
+	}))
+	cli := srv.client(t)
+	srv.reply("auth/login", ResponseBodyFAIL)
+	ss.expire()
+	if _, err := cli.GetVersion(); err == nil {
+		t.Fatal("expected error when re-authentication fails")
+	}
+}
+
+func (s *sessions) logout(w http.ResponseWriter, r *http.Request) {
+	if ck, err := r.Cookie("SID"); err == nil {
+		s.mu.Lock()
+		delete(s.valid, ck.Value)
+		s.mu.Unlock()
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	srv := newFakeServer(t)
+	ss := new(sessions)
+	srv.handle("auth/login", ss.login)
+	srv.handle("auth/logout", ss.guard(ss.logout))
+	srv.handle("app/webapiVersion", ss.guard(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("2.9.3"))
+	}))
+	cli := srv.client(t, WithAuth("admin", "adminadmin"))
+
+	if ok, err := cli.IsAuthenticated(); !ok || err != nil {
+		t.Fatalf("IsAuthenticated = %v, %v", ok, err)
+	}
+	if err := cli.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := cli.IsAuthenticated(); ok || err != nil {
+		t.Fatalf("IsAuthenticated after logout = %v, %v", ok, err)
+	}
+	if _, err := cli.GetApiVersion(); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("request after logout: %v", err)
+	}
+	if n := srv.called("auth/login"); n != 1 {
+		t.Errorf("re-authenticated after logout, login called %d times", n)
+	}
+
+	if err := cli.Login("admin", "adminadmin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.GetApiVersion(); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("request after close: %v", err)
+	}
+}
+
+func TestLoginIPBanned(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle("auth/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Your IP address has been banned after too many failed authentication attempts.", http.StatusForbidden)
+	})
+	_, err := NewClient(srv.URL, WithAuth("admin", "wrong"))
+	if !errors.Is(err, ErrIPBanned) || !errors.Is(err, ErrForbidden) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+package qbt_apiv2
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ExportTorrent returns the .torrent file of a torrent, it requires qBittorrent v4.5.0 or later.
+// It fails with ErrConflict if the torrent has no metadata yet.
+func (c *Client) ExportTorrent(hash string) ([]byte, error) {
+	return c.ExportTorrentContext(context.Background(), hash)
+}
+
+// ExportTorrentContext is like ExportTorrent but uses ctx for the request.
+func (c *Client) ExportTorrentContext(ctx context.Context, hash string) ([]byte, error) {
+	if err := c.requireAPI(ctx, "torrents/export", apiExport); err != nil {
+		return nil, err
+	}
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/export", Optional{
+		"hash": hash,
+	})
+	err = RespOk(resp, err)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// backupVersion is the version of the backup format.
+const backupVersion = 1
+
+// backupManifest is the name of the manifest in a backup archive.
+const backupManifest = "manifest.json"
+
+// BackupManifest describes the content of a backup archive.
+type BackupManifest struct {
+	Version    int                   `json:"version"`
+	Created    time.Time             `json:"created"`
+	Categories map[string]Categories `json:"categories"`
+	Torrents   []BackupTorrent       `json:"torrents"`
+}
+
+// BackupTorrent is a torrent of a backup archive with its settings.
+type BackupTorrent struct {
+	Hash string `json:"hash"`
+	Name string `json:"name"`
+	// Files are the names of the files relative to the save path, which differ from
+	// the ones of the .torrent file after a rename or with another content layout.
+	Files []string `json:"files,omitempty"`
+	// File is the .torrent file in the archive,
+	// empty if the torrent had no metadata and is restored from Magnet.
+	File                     string         `json:"file,omitempty"`
+	Magnet                   string         `json:"magnet,omitempty"`
+	Category                 string         `json:"category,omitempty"`
+	Tags                     []string       `json:"tags,omitempty"`
+	SavePath                 string         `json:"save_path"`
+	DownloadPath             string         `json:"download_path,omitempty"`
+	AutoTMM                  bool           `json:"auto_tmm"`
+	Stopped                  bool           `json:"stopped"`
+	DLLimit                  SpeedLimit     `json:"dl_limit"`
+	UpLimit                  SpeedLimit     `json:"up_limit"`
+	RatioLimit               RatioLimit     `json:"ratio_limit"`
+	SeedingTimeLimit         TimeLimit      `json:"seeding_time_limit"`
+	InactiveSeedingTimeLimit TimeLimit      `json:"inactive_seeding_time_limit"`
+	SequentialDownload       bool           `json:"seq_dl"`
+	FirstLastPiecePrio       bool           `json:"f_l_piece_prio"`
+	FilePriorities           []FilePriority `json:"file_priorities,omitempty"`
+}
+
+// Backup writes a tar archive of every torrent of the server to w: the
+// manifest.json BackupManifest, followed by the exported .torrent files.
+// Wrap w with a gzip.Writer to compress it. The .torrent files are held in memory
+// until the manifest is written. It requires qBittorrent v4.5.0 or later.
+func (c *Client) Backup(w io.Writer) (*BackupManifest, error) {
+	return c.BackupContext(context.Background(), w)
+}
+
+// BackupContext is like Backup but uses ctx for the requests.
+func (c *Client) BackupContext(ctx context.Context, w io.Writer) (*BackupManifest, error) {
+	if err := c.requireAPI(ctx, "torrents/export", apiExport); err != nil {
+		return nil, err
+	}
+	cats, err := c.GetCategoriesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := c.TorrentListContext(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	m := &BackupManifest{
+		Version:    backupVersion,
+		Created:    time.Now().UTC().Truncate(time.Second),
+		Categories: cats,
+		Torrents:   make([]BackupTorrent, 0, len(ts)),
+	}
+	exports := make([][]byte, 0, len(ts))
+	for _, t := range ts {
+		bt, b, err := c.backupTorrent(ctx, t)
+		if err != nil {
+			return nil, fmt.Errorf("backup of torrent %s: %w", t.Hash, err)
+		}
+		m.Torrents = append(m.Torrents, bt)
+		exports = append(exports, b)
+	}
+
+	tw := tar.NewWriter(w)
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err = writeTarFile(tw, backupManifest, manifest, m.Created); err != nil {
+		return nil, err
+	}
+	for i, bt := range m.Torrents {
+		if bt.File == "" {
+			continue
+		}
+		if err = writeTarFile(tw, bt.File, exports[i], m.Created); err != nil {
+			return nil, err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// backupTorrent collects the settings and the .torrent file of t.
+func (c *Client) backupTorrent(ctx context.Context, t Torrent) (BackupTorrent, []byte, error) {
+	bt := BackupTorrent{
+		Hash:                     t.Hash,
+		Name:                     t.Name,
+		Category:                 t.Category,
+		Tags:                     t.Tags,
+		SavePath:                 t.SavePath,
+		DownloadPath:             t.DownloadPath,
+		AutoTMM:                  t.AutoTmm,
+		Stopped:                  strings.HasPrefix(t.State, "paused") || strings.HasPrefix(t.State, "stopped"),
+		DLLimit:                  SpeedLimit(t.DLLimit),
+		UpLimit:                  SpeedLimit(t.UpLimit),
+		RatioLimit:               RatioLimit(t.RatioLimit),
+		SeedingTimeLimit:         TimeLimit(t.SeedingTimeLimit),
+		InactiveSeedingTimeLimit: TimeLimit(t.InactiveSeedingTimeLimit),
+		SequentialDownload:       t.SeqDL,
+		FirstLastPiecePrio:       t.FLPiecePrio,
+	}
+	b, err := c.ExportTorrentContext(ctx, t.Hash)
+	if errors.Is(err, ErrConflict) {
+		// no metadata yet, there are no files either
+		bt.Magnet = t.MagnetURI
+		return bt, nil, nil
+	}
+	if err != nil {
+		return BackupTorrent{}, nil, err
+	}
+	bt.File = "torrents/" + t.Hash + ".torrent"
+	fs, err := c.FilesContext(ctx, t.Hash)
+	if err != nil {
+		return BackupTorrent{}, nil, err
+	}
+	bt.Files = make([]string, len(fs))
+	bt.FilePriorities = make([]FilePriority, len(fs))
+	for i, f := range fs {
+		bt.Files[i] = f.Name
+		bt.FilePriorities[i] = f.Priority
+	}
+	return bt, b, nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, b []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(len(b)),
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(b)
+	return err
+}
+
+// Restore adds the torrents of a backup archive written by Backup, meant for an
+// empty server. The categories are created or updated first, then every torrent
+// is added stopped without rechecking its data, its files are renamed to their
+// backed up names, its settings and file priorities are reapplied, and it is
+// started unless it was stopped. The torrents which already exist are skipped. It returns the
+// number of restored torrents, the failures are reported together in the returned error.
+func (c *Client) Restore(r io.Reader) (int, error) {
+	return c.RestoreContext(context.Background(), r)
+}
+
+// RestoreContext is like Restore but uses ctx for the requests.
+func (c *Client) RestoreContext(ctx context.Context, r io.Reader) (int, error) {
+	tr := tar.NewReader(r)
+	hdr, err := tr.Next()
+	if err != nil {
+		return 0, fmt.Errorf("invalid backup: %w", err)
+	}
+	if hdr.Name != backupManifest {
+		return 0, fmt.Errorf("invalid backup: %s is not the first file", backupManifest)
+	}
+	var m BackupManifest
+	if err = json.NewDecoder(tr).Decode(&m); err != nil {
+		return 0, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	if m.Version != backupVersion {
+		return 0, fmt.Errorf("unsupported backup version %d", m.Version)
+	}
+	for _, cat := range m.Categories {
+		err = c.CreateCategoryContext(ctx, cat)
+		if errors.Is(err, ErrConflict) {
+			err = c.EditCategoryContext(ctx, cat)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("restore of category %q: %w", cat.Name, err)
+		}
+	}
+
+	var (
+		n    int
+		errs []error
+	)
+	for _, bt := range m.Torrents {
+		if err := ctx.Err(); err != nil {
+			return n, errors.Join(append(errs, err)...)
+		}
+		var b []byte
+		if bt.File != "" {
+			hdr, err := tr.Next()
+			if err != nil {
+				return n, errors.Join(append(errs, fmt.Errorf("invalid backup: %w", err))...)
+			}
+			if hdr.Name != bt.File {
+				return n, errors.Join(append(errs, fmt.Errorf("invalid backup: %s instead of %s", hdr.Name, bt.File))...)
+			}
+			if b, err = io.ReadAll(tr); err != nil {
+				return n, errors.Join(append(errs, err)...)
+			}
+		}
+		err := c.restoreTorrent(ctx, bt, b)
+		switch {
+		case errors.Is(err, ErrTorrentExists):
+		case err != nil:
+			errs = append(errs, fmt.Errorf("restore of torrent %s: %w", bt.Hash, err))
+		default:
+			n++
+		}
+	}
+	return n, errors.Join(errs...)
+}
+
+// restoreTorrent adds bt from its .torrent file b, or its magnet link if b is nil.
+func (c *Client) restoreTorrent(ctx context.Context, bt BackupTorrent, b []byte) error {
+	autoTMM := bt.AutoTMM
+	opts := &AddTorrentOptions{
+		SavePath:                 bt.SavePath,
+		DownloadPath:             bt.DownloadPath,
+		Category:                 bt.Category,
+		Tags:                     bt.Tags,
+		Rename:                   bt.Name,
+		SkipChecking:             true,
+		Stopped:                  bt.Stopped,
+		ContentLayout:            ContentLayoutOriginal,
+		UpLimit:                  bt.UpLimit,
+		DLLimit:                  bt.DLLimit,
+		RatioLimit:               bt.RatioLimit,
+		SeedingTimeLimit:         bt.SeedingTimeLimit,
+		InactiveSeedingTimeLimit: bt.InactiveSeedingTimeLimit,
+		AutoTMM:                  &autoTMM,
+		SequentialDownload:       bt.SequentialDownload,
+		FirstLastPiecePrio:       bt.FirstLastPiecePrio,
+	}
+	if b == nil {
+		if bt.Magnet == "" {
+			return errors.New("neither .torrent file nor magnet link")
+		}
+		_, err := c.AddMagnetAndWaitContext(ctx, bt.Magnet, opts)
+		return err
+	}
+	// the data is skipped checking, so it must not be read before the files
+	// have their backed up names
+	opts.Stopped = true
+	t, err := c.AddTorrentFileAndWaitContext(ctx, UploadBytes(bt.Hash+".torrent", b), opts)
+	if err != nil {
+		return err
+	}
+	if len(bt.Files) > 0 {
+		fs, err := c.FilesContext(ctx, t.Hash)
+		if err != nil {
+			return err
+		}
+		for i, f := range fs {
+			if i < len(bt.Files) && bt.Files[i] != "" && f.Name != bt.Files[i] {
+				if err = c.RenameFileContext(ctx, t.Hash, f.Name, bt.Files[i]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// the files are added with normal priority
+	byPrio := make(map[FilePriority][]int)
+	for i, p := range bt.FilePriorities {
+		if p != FilePrioNormal {
+			byPrio[p] = append(byPrio[p], i)
+		}
+	}
+	for p, idxs := range byPrio {
+		if err = c.SetFilePriorityContext(ctx, t.Hash, p, idxs...); err != nil {
+			return err
+		}
+	}
+	if bt.Stopped {
+		return nil
+	}
+	return c.StartTorrentsContext(ctx, t.Hash)
+}
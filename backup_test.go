@@ -0,0 +1,115 @@
+package qbt_apiv2
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBackupRestore(t *testing.T) {
+	const (
+		fileHash   = "fcc009279110bdbda2cc4355f2ae0279e14f5466"
+		magnetHash = "c9e15763f722f23e98a29decdfae341b98d53056"
+	)
+	torrent, err := os.ReadFile("metainfo/testdata/single.torrent")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := newFakeServer(t)
+	src.reply("app/webapiVersion", "2.9.3")
+	src.reply("torrents/categories", `{"iso":{"name":"iso","savePath":"/data/iso","download_path":false}}`)
+	src.reply("torrents/info", `[
+		{"hash":"`+fileHash+`","name":"debian","category":"iso","tags":"linux, x","save_path":"/data/iso",
+			"state":"stalledUP","auto_tmm":true,"ratio_limit":2,"seeding_time_limit":-2,"up_limit":10485760},
+		{"hash":"`+magnetHash+`","name":"m","save_path":"/data","state":"metaDL",
+			"magnet_uri":"magnet:?xt=urn:btih:`+magnetHash+`&dn=m"}]`)
+	src.handle("torrents/export", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("hash") != fileHash {
+			http.Error(w, "", http.StatusConflict)
+			return
+		}
+		w.Write(torrent)
+	})
+	src.reply("torrents/files", `[{"index":0,"name":"debian/debian.iso","priority":0},{"index":1,"name":"b","priority":1},{"index":2,"name":"c","priority":6}]`)
+	var archive bytes.Buffer
+	m, err := src.client(t).Backup(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Torrents) != 2 || m.Torrents[0].File == "" || m.Torrents[1].Magnet == "" ||
+		m.Torrents[0].Stopped || fmt.Sprint(m.Torrents[0].FilePriorities) != "[Do not download Normal High]" {
+		t.Fatalf("manifest = %+v", m)
+	}
+
+	dst := newFakeServer(t)
+	dst.reply("app/webapiVersion", "2.9.3")
+	var (
+		mu    sync.Mutex
+		added = map[string]string{}
+		calls []string
+	)
+	record := func(r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		var kv []string
+		for k, v := range r.Form {
+			kv = append(kv, k+"="+strings.Join(v, ","))
+		}
+		sort.Strings(kv)
+		mu.Lock()
+		calls = append(calls, r.URL.Path[len("/api/v2/"):]+" "+strings.Join(kv, " "))
+		mu.Unlock()
+	}
+	dst.reply("torrents/files", `[{"name":"debian.iso"},{"name":"b"},{"name":"c"}]`)
+	for _, ep := range []string{"torrents/createCategory", "torrents/renameFile", "torrents/filePrio", "torrents/resume"} {
+		dst.handle(ep, func(w http.ResponseWriter, r *http.Request) { record(r) })
+	}
+	dst.handle("torrents/add", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		var h InfoHash
+		if r.FormValue("urls") != "" {
+			h, _ = MagnetInfoHash(r.FormValue("urls"))
+		} else {
+			f, _, _ := r.FormFile("torrents")
+			var b bytes.Buffer
+			b.ReadFrom(f)
+			h, _ = TorrentInfoHash(b.Bytes())
+		}
+		mu.Lock()
+		added[h.ID()] = r.FormValue("rename")
+		mu.Unlock()
+		w.Write([]byte(ResponseBodyOK))
+	})
+	dst.handle("torrents/info", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		h := r.FormValue("hashes")
+		if name, ok := added[h]; ok {
+			fmt.Fprintf(w, `[{"hash":%q,"name":%q}]`, h, name)
+			return
+		}
+		w.Write([]byte("[]"))
+	})
+	n, err := dst.client(t).Restore(&archive)
+	if n != 2 || err != nil {
+		t.Fatalf("Restore = %d, %v", n, err)
+	}
+	want := []string{
+		"torrents/createCategory category=iso downloadPathEnabled=false savePath=/data/iso",
+		"torrents/add autoTMM=true category=iso contentLayout=Original paused=true ratioLimit=2 rename=debian savepath=/data/iso seedingTimeLimit=-2 skip_checking=true tags=linux,x upLimit=10485760",
+		"torrents/renameFile hash=" + fileHash + " newPath=debian/debian.iso oldPath=debian.iso",
+		"torrents/filePrio hash=" + fileHash + " id=0 priority=0",
+		"torrents/filePrio hash=" + fileHash + " id=2 priority=6",
+		"torrents/resume hashes=" + fileHash,
+		"torrents/add autoTMM=false contentLayout=Original rename=m savepath=/data skip_checking=true urls=magnet:?xt=urn:btih:" + magnetHash + "&dn=m",
+	}
+	sort.Strings(calls[3:5])
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,81 @@
+package bencode
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	v, err := Decode([]byte("d4:listli-3e3:abce3:numi42e3:strd1:ai0eee"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decode([]byte(strings.Repeat("l", maxDepth) + strings.Repeat("e", maxDepth))); err != nil {
+		t.Errorf("Decode of %d nested lists: %v", maxDepth, err)
+	}
+	want := map[string]any{
+		"list": []any{int64(-3), "abc"},
+		"num":  int64(42),
+		"str":  map[string]any{"a": int64(0)},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Decode = %#v", v)
+	}
+	for _, bad := range []string{
+		"", "i", "i01e", "i-0e", "ie", "3:ab", "l", "d1:a", "di1ei2ee", "i1ei2e", "x", "-1:a",
+		"i+1e", "i-e", "01:a", "d01:ai1ee",
+		strings.Repeat("l", maxDepth+1) + strings.Repeat("e", maxDepth+1),
+		strings.Repeat("d1:a", maxDepth+1) + "i0e" + strings.Repeat("e", maxDepth+1),
+		"d1:a" + strings.Repeat("l", 10000000),
+	} {
+		if _, err := Decode([]byte(bad)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Decode(%q) = %v, want a syntax error", bad, err)
+		}
+	}
+}
+
+type inner struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+}
+
+type outer struct {
+	Name    string     `bencode:"name"`
+	Private bool       `bencode:"private,omitempty"`
+	Files   []inner    `bencode:"files,omitempty"`
+	Raw     RawMessage `bencode:"raw,omitempty"`
+	Pieces  []byte     `bencode:"pieces"`
+	Ignored string     `bencode:"-"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := outer{
+		Name:    "x",
+		Private: true,
+		Files:   []inner{{1, []string{"a", "b"}}, {2, []string{"c"}}},
+		Raw:     RawMessage("li1ee"),
+		Pieces:  []byte{0, 1, 2},
+		Ignored: "y",
+	}
+	b, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "d5:filesld6:lengthi1e4:pathl1:a1:beed6:lengthi2e4:pathl1:ceee4:name1:x6:pieces3:\x00\x01\x027:privatei1e3:rawli1eee"
+	if string(b) != want {
+		t.Errorf("Marshal = %q", b)
+	}
+	var out outer
+	if err = Unmarshal(append(b[:len(b)-1:len(b)-1], "7:unknownli1eee"...), &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Ignored = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("Unmarshal = %+v", out)
+	}
+	if err = Unmarshal([]byte("d4:Name3:abce"), &struct{ Name int }{}); err == nil {
+		t.Error("decoded a string into an int")
+	}
+}
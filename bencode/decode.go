@@ -0,0 +1,312 @@
+// Package bencode implements the bencoding of BitTorrent metainfo files (BEP 3).
+//
+// Integers are decoded to int64, byte strings to string, lists to []any and
+// dictionaries to map[string]any when the destination is an interface.
+// Structs are matched with their fields by the `bencode:"key"` tag,
+// or the field name, and unknown keys are skipped.
+package bencode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ErrSyntax is matched by the errors of malformed input.
+var ErrSyntax = errors.New("bencode: syntax error")
+
+// RawMessage is a raw encoded value, it delays decoding or is encoded as is.
+type RawMessage []byte
+
+// Unmarshaler is implemented by the types which decode themselves,
+// b is the encoded value.
+type Unmarshaler interface {
+	UnmarshalBencode(b []byte) error
+}
+
+// Unmarshal decodes the value b into v, a non-nil pointer.
+// b must hold exactly one value.
+func Unmarshal(b []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("bencode: Unmarshal needs a non-nil pointer, got %T", v)
+	}
+	d := decoder{b: b}
+	if err := d.value(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(b) {
+		return d.syntaxErr("trailing data")
+	}
+	return nil
+}
+
+// Decode decodes b into the interface representation of its value.
+func Decode(b []byte) (any, error) {
+	var v any
+	err := Unmarshal(b, &v)
+	return v, err
+}
+
+// maxDepth bounds the nesting of lists and dictionaries,
+// so hostile input cannot overflow the stack.
+const maxDepth = 1000
+
+type decoder struct {
+	b     []byte
+	pos   int
+	depth int
+}
+
+// nest enters a list or dictionary, leave must be called when it is decoded.
+func (d *decoder) nest() error {
+	if d.depth++; d.depth > maxDepth {
+		return d.syntaxErr(fmt.Sprintf("nested deeper than %d", maxDepth))
+	}
+	return nil
+}
+
+func (d *decoder) leave() {
+	d.depth--
+}
+
+func (d *decoder) syntaxErr(msg string) error {
+	return fmt.Errorf("%w at offset %d: %s", ErrSyntax, d.pos, msg)
+}
+
+func typeErr(kind string, t reflect.Type) error {
+	return fmt.Errorf("bencode: cannot decode %s into %s", kind, t)
+}
+
+var (
+	rawType         = reflect.TypeOf(RawMessage(nil))
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+// value decodes the value at d.pos into rv.
+func (d *decoder) value(rv reflect.Value) error {
+	if d.pos >= len(d.b) {
+		return d.syntaxErr("unexpected end of input")
+	}
+	if rv.Type() == rawType || rv.CanAddr() && rv.Addr().Type().Implements(unmarshalerType) {
+		start := d.pos
+		if err := d.skip(); err != nil {
+			return err
+		}
+		raw := d.b[start:d.pos]
+		if rv.Type() == rawType {
+			rv.SetBytes(append([]byte(nil), raw...))
+			return nil
+		}
+		return rv.Addr().Interface().(Unmarshaler).UnmarshalBencode(raw)
+	}
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.value(rv.Elem())
+	}
+	switch c := d.b[d.pos]; {
+	case c == 'i':
+		return d.integer(rv)
+	case c >= '0' && c <= '9':
+		return d.str(rv)
+	case c == 'l':
+		return d.list(rv)
+	case c == 'd':
+		return d.dict(rv)
+	default:
+		return d.syntaxErr(fmt.Sprintf("unexpected %q", c))
+	}
+}
+
+// skip moves past the value at d.pos.
+func (d *decoder) skip() error {
+	var v any
+	return d.value(reflect.ValueOf(&v).Elem())
+}
+
+func (d *decoder) readInt() (int64, error) {
+	end := bytes.IndexByte(d.b[d.pos:], 'e')
+	if end == -1 {
+		return 0, d.syntaxErr("unterminated integer")
+	}
+	s := string(d.b[d.pos+1 : d.pos+end])
+	// leading zeros, negative zero and a plus sign are invalid
+	if s == "" || s == "-0" || s[0] == '+' || len(s) > 1 && s[0] == '0' || len(s) > 2 && s[:2] == "-0" {
+		return 0, d.syntaxErr(fmt.Sprintf("invalid integer %q", s))
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, d.syntaxErr(fmt.Sprintf("invalid integer %q", s))
+	}
+	d.pos += end + 1
+	return n, nil
+}
+
+func (d *decoder) integer(rv reflect.Value) error {
+	n, err := d.readInt()
+	if err != nil {
+		return err
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.OverflowInt(n) {
+			return typeErr("integer "+strconv.FormatInt(n, 10), rv.Type())
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n < 0 || rv.OverflowUint(uint64(n)) {
+			return typeErr("integer "+strconv.FormatInt(n, 10), rv.Type())
+		}
+		rv.SetUint(uint64(n))
+	case reflect.Bool:
+		rv.SetBool(n != 0)
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return typeErr("integer", rv.Type())
+		}
+		rv.Set(reflect.ValueOf(n))
+	default:
+		return typeErr("integer", rv.Type())
+	}
+	return nil
+}
+
+func (d *decoder) readStr() ([]byte, error) {
+	colon := bytes.IndexByte(d.b[d.pos:], ':')
+	if colon == -1 {
+		return nil, d.syntaxErr("invalid string length")
+	}
+	ls := string(d.b[d.pos : d.pos+colon])
+	n, err := strconv.Atoi(ls)
+	// leading zeros are invalid
+	if err != nil || n < 0 || len(ls) > 1 && ls[0] == '0' || n > len(d.b)-d.pos-colon-1 {
+		return nil, d.syntaxErr("invalid string length")
+	}
+	start := d.pos + colon + 1
+	d.pos = start + n
+	return d.b[start:d.pos], nil
+}
+
+func (d *decoder) str(rv reflect.Value) error {
+	s, err := d.readStr()
+	if err != nil {
+		return err
+	}
+	switch {
+	case rv.Kind() == reflect.String:
+		rv.SetString(string(s))
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		rv.SetBytes(append([]byte(nil), s...))
+	case rv.Kind() == reflect.Interface && rv.NumMethod() == 0:
+		rv.Set(reflect.ValueOf(string(s)))
+	default:
+		return typeErr("string", rv.Type())
+	}
+	return nil
+}
+
+func (d *decoder) list(rv reflect.Value) error {
+	if err := d.nest(); err != nil {
+		return err
+	}
+	defer d.leave()
+	d.pos++
+	switch {
+	case rv.Kind() == reflect.Slice:
+		s := reflect.MakeSlice(rv.Type(), 0, 0)
+		for d.pos < len(d.b) && d.b[d.pos] != 'e' {
+			e := reflect.New(rv.Type().Elem()).Elem()
+			if err := d.value(e); err != nil {
+				return err
+			}
+			s = reflect.Append(s, e)
+		}
+		rv.Set(s)
+	case rv.Kind() == reflect.Interface && rv.NumMethod() == 0:
+		l := []any{}
+		for d.pos < len(d.b) && d.b[d.pos] != 'e' {
+			var e any
+			if err := d.value(reflect.ValueOf(&e).Elem()); err != nil {
+				return err
+			}
+			l = append(l, e)
+		}
+		rv.Set(reflect.ValueOf(l))
+	default:
+		return typeErr("list", rv.Type())
+	}
+	if d.pos >= len(d.b) {
+		return d.syntaxErr("unterminated list")
+	}
+	d.pos++
+	return nil
+}
+
+func (d *decoder) dict(rv reflect.Value) error {
+	if err := d.nest(); err != nil {
+		return err
+	}
+	defer d.leave()
+	var set func(key string) (reflect.Value, bool)
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		set = func(string) (reflect.Value, bool) {
+			return reflect.New(rv.Type().Elem()).Elem(), true
+		}
+	case rv.Kind() == reflect.Interface && rv.NumMethod() == 0:
+		m := map[string]any{}
+		rv.Set(reflect.ValueOf(m))
+		rv = reflect.ValueOf(m)
+		set = func(string) (reflect.Value, bool) {
+			return reflect.New(rv.Type().Elem()).Elem(), true
+		}
+	case rv.Kind() == reflect.Struct:
+		fields := structFields(rv.Type())
+		set = func(key string) (reflect.Value, bool) {
+			for _, f := range fields {
+				if f.name == key {
+					return rv.FieldByIndex(f.index), false
+				}
+			}
+			return reflect.Value{}, false
+		}
+	default:
+		return typeErr("dictionary", rv.Type())
+	}
+	d.pos++
+	for d.pos < len(d.b) && d.b[d.pos] != 'e' {
+		if d.b[d.pos] < '0' || d.b[d.pos] > '9' {
+			return d.syntaxErr("dictionary key is not a string")
+		}
+		k, err := d.readStr()
+		if err != nil {
+			return err
+		}
+		key := string(k)
+		v, isMapElem := set(key)
+		if !v.IsValid() {
+			if err := d.skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.value(v); err != nil {
+			return fmt.Errorf("%w (key %q)", err, key)
+		}
+		if isMapElem {
+			rv.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), v)
+		}
+	}
+	if d.pos >= len(d.b) {
+		return d.syntaxErr("unterminated dictionary")
+	}
+	d.pos++
+	return nil
+}
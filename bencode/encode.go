@@ -0,0 +1,170 @@
+package bencode
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Marshaler is implemented by the types which encode themselves.
+type Marshaler interface {
+	MarshalBencode() ([]byte, error)
+}
+
+// Marshal returns the encoding of v. Integers and booleans are encoded as
+// integers, strings and byte slices as strings, slices and arrays as lists,
+// and maps with string keys and structs as dictionaries with sorted keys.
+// Struct fields tagged with `omitempty` are left out when they are empty,
+// as are nil pointers and interfaces.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var marshalerType = reflect.TypeOf((*Marshaler)(nil)).Elem()
+
+func encode(buf *bytes.Buffer, rv reflect.Value) error {
+	if !rv.IsValid() {
+		return fmt.Errorf("bencode: cannot encode nil")
+	}
+	if rv.Type() == rawType {
+		buf.Write(rv.Bytes())
+		return nil
+	}
+	if rv.Type().Implements(marshalerType) {
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return fmt.Errorf("bencode: cannot encode nil %s", rv.Type())
+		}
+		b, err := rv.Interface().(Marshaler).MarshalBencode()
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return fmt.Errorf("bencode: cannot encode nil %s", rv.Type())
+		}
+		return encode(buf, rv.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprintf(buf, "i%de", rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fmt.Fprintf(buf, "i%de", rv.Uint())
+	case reflect.Bool:
+		if rv.Bool() {
+			buf.WriteString("i1e")
+		} else {
+			buf.WriteString("i0e")
+		}
+	case reflect.String:
+		encodeStr(buf, rv.String())
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			encodeStr(buf, string(b))
+			return nil
+		}
+		buf.WriteByte('l')
+		for i := 0; i < rv.Len(); i++ {
+			if err := encode(buf, rv.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("bencode: cannot encode %s, keys must be strings", rv.Type())
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		buf.WriteByte('d')
+		for _, k := range keys {
+			v := rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()))
+			if isNil(v) {
+				continue
+			}
+			encodeStr(buf, k)
+			if err := encode(buf, v); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	case reflect.Struct:
+		fields := structFields(rv.Type())
+		sort.Slice(fields, func(i, j int) bool {
+			return fields[i].name < fields[j].name
+		})
+		buf.WriteByte('d')
+		for _, f := range fields {
+			v := rv.FieldByIndex(f.index)
+			if isNil(v) || f.omitEmpty && v.IsZero() {
+				continue
+			}
+			encodeStr(buf, f.name)
+			if err := encode(buf, v); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('e')
+	default:
+		return fmt.Errorf("bencode: cannot encode %s", rv.Type())
+	}
+	return nil
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+func encodeStr(buf *bytes.Buffer, s string) {
+	buf.WriteString(strconv.Itoa(len(s)))
+	buf.WriteByte(':')
+	buf.WriteString(s)
+}
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields returns the exported fields of t with their keys.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("bencode")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{
+			name:      name,
+			index:     sf.Index,
+			omitEmpty: opts == "omitempty",
+		})
+	}
+	return fields
+}
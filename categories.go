@@ -0,0 +1,150 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DownloadPathMode tells where a category keeps its incomplete torrents.
+type DownloadPathMode int
+
+const (
+	DownloadPathDefault  DownloadPathMode = iota // follow the global download path setting
+	DownloadPathDisabled                         // download directly to the save path
+	DownloadPathEnabled                          // download to the Path of the DownloadPath
+)
+
+// DownloadPath is the download path setting of a category, the server sends
+// no `download_path` for DownloadPathDefault, false for DownloadPathDisabled
+// and the path for DownloadPathEnabled.
+type DownloadPath struct {
+	Mode DownloadPathMode
+	// Path is the download path, empty for the default download path.
+	Path string
+}
+
+func (p *DownloadPath) UnmarshalJSON(b []byte) error {
+	switch s := strings.TrimSpace(string(b)); s {
+	case "null":
+		*p = DownloadPath{}
+	case "false":
+		*p = DownloadPath{Mode: DownloadPathDisabled}
+	case "true":
+		*p = DownloadPath{Mode: DownloadPathEnabled}
+	default:
+		var path string
+		if err := json.Unmarshal(b, &path); err != nil {
+			return fmt.Errorf("invalid download_path %s: %w", s, err)
+		}
+		*p = DownloadPath{Mode: DownloadPathEnabled, Path: path}
+	}
+	return nil
+}
+
+func (p DownloadPath) MarshalJSON() ([]byte, error) {
+	switch p.Mode {
+	case DownloadPathDisabled:
+		return []byte("false"), nil
+	case DownloadPathEnabled:
+		return json.Marshal(p.Path)
+	default:
+		return []byte("null"), nil
+	}
+}
+
+// GetCategories returns the categories by name.
+func (c *Client) GetCategories() (map[string]Categories, error) {
+	return c.GetCategoriesContext(context.Background())
+}
+
+// GetCategoriesContext is like GetCategories but uses ctx for the request.
+func (c *Client) GetCategoriesContext(ctx context.Context) (map[string]Categories, error) {
+	var cats map[string]Categories
+	err := c.getJSON(ctx, "torrents/categories", nil, &cats)
+	if err != nil {
+		return nil, err
+	}
+	return cats, nil
+}
+
+// CreateCategory creates the category cat.Name.
+// It fails with ErrConflict if the name is invalid or the category exists.
+func (c *Client) CreateCategory(cat Categories) error {
+	return c.CreateCategoryContext(context.Background(), cat)
+}
+
+// CreateCategoryContext is like CreateCategory but uses ctx for the request.
+func (c *Client) CreateCategoryContext(ctx context.Context, cat Categories) error {
+	return c.postAction(ctx, "torrents/createCategory", categoryOpt(cat))
+}
+
+// EditCategory replaces the save path and download path setting of the category cat.Name.
+// It fails with ErrConflict if the category does not exist.
+func (c *Client) EditCategory(cat Categories) error {
+	return c.EditCategoryContext(context.Background(), cat)
+}
+
+// EditCategoryContext is like EditCategory but uses ctx for the request.
+func (c *Client) EditCategoryContext(ctx context.Context, cat Categories) error {
+	return c.postAction(ctx, "torrents/editCategory", categoryOpt(cat))
+}
+
+func categoryOpt(cat Categories) Optional {
+	opt := Optional{
+		"category": cat.Name,
+		"savePath": cat.SavePath,
+	}
+	switch cat.DownloadPath.Mode {
+	case DownloadPathDisabled:
+		opt["downloadPathEnabled"] = false
+	case DownloadPathEnabled:
+		opt["downloadPathEnabled"] = true
+		opt["downloadPath"] = cat.DownloadPath.Path
+	}
+	return opt
+}
+
+// SetCategory sets the category of torrents, the empty category removes it.
+// It fails with ErrConflict if the category does not exist.
+func (c *Client) SetCategory(category string, hashes ...string) error {
+	return c.SetCategoryContext(context.Background(), category, hashes...)
+}
+
+// SetCategoryContext is like SetCategory but uses ctx for the request.
+func (c *Client) SetCategoryContext(ctx context.Context, category string, hashes ...string) error {
+	return c.postAction(ctx, "torrents/setCategory", Optional{
+		"hashes":   strings.Join(hashes, "|"),
+		"category": category,
+	})
+}
+
+// EnsureCategory creates the category name with savePath,
+// or changes its save path if it exists with another one.
+// The download path setting of an existing category is kept.
+func (c *Client) EnsureCategory(name, savePath string) error {
+	return c.EnsureCategoryContext(context.Background(), name, savePath)
+}
+
+// EnsureCategoryContext is like EnsureCategory but uses ctx for the requests.
+func (c *Client) EnsureCategoryContext(ctx context.Context, name, savePath string) error {
+	cats, err := c.GetCategoriesContext(ctx)
+	if err != nil {
+		return err
+	}
+	cat, ok := cats[name]
+	if !ok {
+		cat = Categories{Name: name, SavePath: savePath}
+		err = c.CreateCategoryContext(ctx, cat)
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+		// created meanwhile, or the name is invalid and editing fails too
+	} else if cat.SavePath == savePath {
+		return nil
+	}
+	cat.SavePath = savePath
+	return c.EditCategoryContext(ctx, cat)
+}
@@ -0,0 +1,60 @@
+package qbt_apiv2
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestGetCategories(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("torrents/categories", `{
+		"movies":{"name":"movies","savePath":"/data/movies"},
+		"tv":{"name":"tv","savePath":"/data/tv","download_path":false},
+		"iso":{"name":"iso","savePath":"","download_path":"/incomplete/iso"}}`)
+	cli := srv.client(t)
+	cats, err := cli.GetCategories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cats) != 3 || cats["movies"].DownloadPath.Mode != DownloadPathDefault ||
+		cats["tv"].DownloadPath.Mode != DownloadPathDisabled ||
+		cats["iso"].DownloadPath != (DownloadPath{DownloadPathEnabled, "/incomplete/iso"}) {
+		t.Errorf("unexpected categories: %+v", cats)
+	}
+}
+
+func TestEnsureCategory(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("torrents/categories", `{"tv":{"name":"tv","savePath":"/data/tv","download_path":"/incomplete"}}`)
+	var form url.Values
+	for _, ep := range []string{"torrents/createCategory", "torrents/editCategory"} {
+		srv.handle(ep, func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm
+		})
+	}
+	cli := srv.client(t)
+
+	if err := cli.EnsureCategory("tv", "/data/tv"); err != nil {
+		t.Fatal(err)
+	}
+	if srv.called("torrents/createCategory")+srv.called("torrents/editCategory") != 0 {
+		t.Error("unchanged category was written")
+	}
+
+	if err := cli.EnsureCategory("tv", "/data/series"); err != nil {
+		t.Fatal(err)
+	}
+	if srv.called("torrents/editCategory") != 1 || form.Get("savePath") != "/data/series" ||
+		form.Get("downloadPathEnabled") != "true" || form.Get("downloadPath") != "/incomplete" {
+		t.Errorf("editCategory form = %v", form)
+	}
+
+	if err := cli.EnsureCategory("movies", "/data/movies"); err != nil {
+		t.Fatal(err)
+	}
+	if srv.called("torrents/createCategory") != 1 || form.Get("category") != "movies" {
+		t.Errorf("createCategory form = %v", form)
+	}
+}
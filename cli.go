@@ -2,21 +2,40 @@ package qbt_apiv2
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
-	"net/http/cookiejar"
 	"net/url"
-	"os"
-	"path"
-
-	"golang.org/x/net/publicsuffix"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
+// Client is safe for concurrent use by multiple goroutines.
 type Client struct {
-	httpCli *http.Client
-	URL     string
+	httpCli   *http.Client
+	URL       string
+	userAgent string
+	closed    atomic.Bool
+
+	// authMu guards the fields below and serializes logins
+	authMu sync.Mutex
+	// credentials of the last successful login, used to re-authenticate
+	username, password string
+	// reauth is set by Login and cleared by Logout
+	reauth bool
+	// session is incremented on every successful login
+	session uint64
+
+	// verMu guards the cached web API version, which is reset on login
+	verMu  sync.Mutex
+	apiVer *apiVersion
+
+	// syncMu guards the state of `sync/maindata`
+	syncMu sync.Mutex
 	// API `sync/maindata`` Parameter `rid`
 	rid      int
 	mainData *MainData
@@ -24,53 +43,77 @@ type Client struct {
 
 // NewCli v2
 func NewCli(url string, auth ...string) (*Client, error) {
-	client := new(Client)
-
-	// ensure url ends with "/"
-	if url[len(url)-1:] != "/" {
-		url += "/"
-	}
-
-	client.URL = url + "api/v2/"
+	return NewCliContext(context.Background(), url, auth...)
+}
 
-	// create cookie jar
-	cliJar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
-	client.httpCli = &http.Client{
-		Jar: cliJar,
+// NewCliContext is like NewCli but performs the initial login with ctx.
+func NewCliContext(ctx context.Context, url string, auth ...string) (*Client, error) {
+	var opts []Option
+	if len(auth) != 0 {
+		opts = append(opts, WithAuth(auth[0], auth[1]))
 	}
+	return NewClientContext(ctx, url, opts...)
+}
 
-	nreq := len(auth) == 0
+// NewClient creates a Client configured by opts and logs in.
+func NewClient(url string, opts ...Option) (*Client, error) {
+	return NewClientContext(context.Background(), url, opts...)
+}
 
-	var (
-		err error
-	)
-	if nreq {
-		err = client.Login("", "")
-	} else {
-		err = client.Login(auth[0], auth[1])
+// NewClientContext is like NewClient but performs the initial login with ctx.
+func NewClientContext(ctx context.Context, url string, opts ...Option) (*Client, error) {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
 	}
+	hc, err := o.httpClient()
 	if err != nil {
 		return nil, err
 	}
-
+	client := &Client{
+		httpCli:   hc,
+		URL:       o.apiURL(url),
+		userAgent: o.userAgent,
+	}
+	if err = client.LoginContext(ctx, o.username, o.password); err != nil {
+		return nil, err
+	}
 	return client, nil
 }
 
-// Common Methods for HTTP Requests
-// Use POST request to send x-www-form-urlencoded encoding.
-func (c *Client) postXwwwFormUrlencoded(endpoint string, opts Optional) (*http.Response, error) {
-	values := url.Values{}
-	for k, v := range opts.StringField() {
-		values.Set(k, v)
-	}
-
-	req, err := http.NewRequest("POST", c.URL+endpoint, bytes.NewBufferString(values.Encode()))
+// newRequest creates a POST request to endpoint with the headers shared by all requests.
+func (c *Client) newRequest(ctx context.Context, endpoint string, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL+endpoint, body)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
+	req.Header.Set("Content-Type", contentType)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return req, nil
+}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+// bodyFunc creates the body of a request and its content type.
+// It is called for every attempt, so a request can be replayed after re-authentication.
+type bodyFunc func() (body io.Reader, contentType string, err error)
 
+// send performs a single attempt of a request.
+func (c *Client) send(ctx context.Context, endpoint string, body bodyFunc) (*http.Response, error) {
+	b, contentType, err := body()
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest(ctx, endpoint, b, contentType)
+	if err != nil {
+		if cl, ok := b.(io.Closer); ok {
+			cl.Close()
+		}
+		return nil, err
+	}
+	if s, ok := b.(*sizedReader); ok {
+		req.ContentLength = s.size
+	}
 	resp, err := c.httpCli.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform request: %w", err)
@@ -78,6 +121,73 @@ func (c *Client) postXwwwFormUrlencoded(endpoint string, opts Optional) (*http.R
 	return resp, nil
 }
 
+// do performs a request. When the WebUI answers 403 Forbidden the session
+// has most likely expired (`web_ui_session_timeout`), so do logs in again
+// with the remembered credentials and replays the request once.
+// A response with a status other than 200 OK is returned as an *APIError
+// built from opts.
+func (c *Client) do(ctx context.Context, endpoint string, opts Optional, body bodyFunc) (*http.Response, error) {
+	if c.closed.Load() {
+		return nil, ErrClientClosed
+	}
+	// requests to "auth/" run while authMu is held by login
+	auth := strings.HasPrefix(endpoint, "auth/")
+	var session uint64
+	if !auth {
+		c.authMu.Lock()
+		session = c.session
+		c.authMu.Unlock()
+	}
+	resp, err := c.send(ctx, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusForbidden && !auth {
+		apiErr := newAPIError(resp, endpoint, redactedParams(opts))
+		if errors.Is(apiErr, ErrIPBanned) {
+			return nil, apiErr
+		}
+		ok, err := c.relogin(ctx, session)
+		if err != nil {
+			return nil, fmt.Errorf("re-authentication failed: %w", err)
+		}
+		if !ok {
+			return nil, c.notLoggedIn(ctx, apiErr)
+		}
+		if resp, err = c.send(ctx, endpoint, body); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, endpoint, redactedParams(opts))
+	}
+	return resp, nil
+}
+
+// notLoggedIn sets the Err of apiErr, a 403 Forbidden which cannot be replayed,
+// to ErrNotLoggedIn if the session is not authenticated, so it is not mistaken
+// for the 403 of the endpoint, e.g. no write access of `torrents/setLocation`.
+func (c *Client) notLoggedIn(ctx context.Context, apiErr *APIError) *APIError {
+	if ok, err := c.IsAuthenticatedContext(ctx); err == nil && !ok {
+		apiErr.Err = ErrNotLoggedIn
+	}
+	return apiErr
+}
+
+// Common Methods for HTTP Requests
+// Use POST request to send x-www-form-urlencoded encoding.
+func (c *Client) postXwwwFormUrlencoded(ctx context.Context, endpoint string, opts Optional) (*http.Response, error) {
+	values := url.Values{}
+	for k, v := range opts.StringField() {
+		values.Set(k, v)
+	}
+	encoded := values.Encode()
+
+	return c.do(ctx, endpoint, opts, func() (io.Reader, string, error) {
+		return strings.NewReader(encoded), "application/x-www-form-urlencoded", nil
+	})
+}
+
 // writeOptions will write a map to the buffer through multipart.NewWriter
 func writeOptions(writer *multipart.Writer, opts Optional) {
 	ws := opts.StringField()
@@ -87,25 +197,16 @@ func writeOptions(writer *multipart.Writer, opts Optional) {
 }
 
 // postMultipart will perform a multiple part POST request
-func (c *Client) postMultipart(endpoint string, buffer bytes.Buffer, contentType string) (*http.Response, error) {
-	req, err := http.NewRequest("POST", c.URL+endpoint, &buffer)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
+func (c *Client) postMultipart(ctx context.Context, endpoint string, buffer bytes.Buffer, contentType string, opts Optional) (*http.Response, error) {
+	b := buffer.Bytes()
 	// add the content-type so qbittorrent knows what to expect
-	req.Header.Set("Content-Type", contentType)
-
-	resp, err := c.httpCli.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to perform request: %w", err)
-	}
-
-	return resp, nil
+	return c.do(ctx, endpoint, opts, func() (io.Reader, string, error) {
+		return bytes.NewReader(b), contentType, nil
+	})
 }
 
 // postMultipartData will perform a multiple part POST request without a file
-func (c *Client) postMultipartData(endpoint string, opts Optional) (*http.Response, error) {
+func (c *Client) postMultipartData(ctx context.Context, endpoint string, opts Optional) (*http.Response, error) {
 	var buffer bytes.Buffer
 	writer := multipart.NewWriter(&buffer)
 
@@ -118,7 +219,7 @@ func (c *Client) postMultipartData(endpoint string, opts Optional) (*http.Respon
 		return nil, fmt.Errorf("failed to close writer: %w", err)
 	}
 
-	resp, err := c.postMultipart(endpoint, buffer, writer.FormDataContentType())
+	resp, err := c.postMultipart(ctx, endpoint, buffer, writer.FormDataContentType(), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -127,42 +228,12 @@ func (c *Client) postMultipartData(endpoint string, opts Optional) (*http.Respon
 }
 
 // postMultipartFile will perform a multiple part POST request with a file
-func (c *Client) postMultipartFile(endpoint string, fileName string, opts Optional) (*http.Response, error) {
-	var buffer bytes.Buffer
-	writer := multipart.NewWriter(&buffer)
-
-	// open the file for reading
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, fmt.Errorf("error opening file: %w", err)
-	}
-	// defer the closing of the file until the end of function
-	// so we can still copy its contents
-	defer file.Close()
-
-	// create form for writing the file to and give it the filename
-	formWriter, err := writer.CreateFormFile("torrents", path.Base(fileName))
-	if err != nil {
-		return nil, fmt.Errorf("error adding file: %w", err)
-	}
-
-	// write the options to the buffer
-	writeOptions(writer, opts)
-
-	// copy the file contents into the form
-	if _, err = io.Copy(formWriter, file); err != nil {
-		return nil, fmt.Errorf("error copying file: %w", err)
-	}
-
-	// close the writer before doing request to get closing line on multipart request
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close writer: %w", err)
-	}
-
-	resp, err := c.postMultipart(endpoint, buffer, writer.FormDataContentType())
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) postMultipartFile(ctx context.Context, endpoint string, fileName string, opts Optional) (*http.Response, error) {
+	return c.postMultipartStream(ctx, endpoint, opts, UploadFile(fileName))
+}
 
-	return resp, nil
+// postMultipartStream will perform a multiple part POST request with files,
+// which are streamed instead of being buffered
+func (c *Client) postMultipartStream(ctx context.Context, endpoint string, opts Optional, files ...TorrentUpload) (*http.Response, error) {
+	return c.do(ctx, endpoint, opts, multipartStream(opts, files))
 }
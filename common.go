@@ -24,11 +24,13 @@ func (opt Optional) StringField() map[string]string {
 	return m
 }
 
+// RespOk returns err if it is not nil, otherwise an *APIError
+// if resp does not have the status 200 OK.
 func RespOk(resp *http.Response, err error) error {
 	if err != nil {
 		return err
-	} else if resp.Status != "200 OK" { // check for correct status code
-		return fmt.Errorf("%w: %s", ErrBadResponse, resp.Status)
+	} else if resp.StatusCode != http.StatusOK { // check for correct status code
+		return newAPIError(resp, "", nil)
 	} else {
 		return nil
 	}
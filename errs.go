@@ -1,6 +1,13 @@
 package qbt_apiv2
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
 
 var (
 	ErrBadResponse = errors.New("bad response")
@@ -9,3 +16,149 @@ var (
 
 	ErrAddTorrnetfailed = errors.New("add torrnet failed")
 )
+
+// Errors matching the status code of an *APIError, usable with errors.Is.
+var (
+	ErrBadRequest           = errors.New("bad request")
+	ErrForbidden            = errors.New("forbidden")
+	ErrNotFound             = errors.New("not found")
+	ErrConflict             = errors.New("conflict")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+var (
+	// ErrIPBanned is returned when the WebUI has banned the IP address of the client
+	// after too many failed logins (`web_ui_max_auth_fail_count`),
+	// it also matches ErrForbidden.
+	ErrIPBanned = errors.New("ip address banned by the WebUI")
+
+	ErrClientClosed = errors.New("client closed")
+
+	// ErrNotLoggedIn is returned when the WebUI refuses a request because the
+	// client is not logged in and has no credentials to login again,
+	// it also matches ErrForbidden.
+	ErrNotLoggedIn = errors.New("not logged in")
+
+	// ErrNotSupported is returned when the web API of the server is too old for a method.
+	ErrNotSupported = errors.New("not supported by this server")
+
+	// ErrNoFileMatched is returned by DownloadOnly when no file of the torrent matches.
+	ErrNoFileMatched = errors.New("no file matched")
+
+	// ErrQueueingDisabled is returned by the queue methods when torrent queueing
+	// is disabled in the preferences, it also matches ErrConflict.
+	ErrQueueingDisabled = errors.New("torrent queueing is disabled")
+
+	// ErrTorrentExists is returned when adding a torrent which the server already has.
+	ErrTorrentExists = errors.New("torrent already exists")
+)
+
+// Errors of SetLocation, SetSavePath and SetDownloadPath,
+// they also match ErrBadRequest, ErrForbidden and ErrConflict.
+var (
+	ErrEmptyPath       = errors.New("path is empty")
+	ErrPathNotWritable = errors.New("no write access to the path")
+	ErrCreateDirFailed = errors.New("unable to create the directory")
+)
+
+func statusErr(code int) error {
+	switch code {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusUnsupportedMediaType:
+		return ErrUnsupportedMediaType
+	default:
+		return ErrBadResponse
+	}
+}
+
+// APIError is returned when the WebUI responds with a status other than 200 OK.
+// It matches ErrBadResponse and the error of its status code, e.g. ErrNotFound,
+// so callers can branch on it with errors.Is or inspect it with errors.As.
+type APIError struct {
+	StatusCode int
+	Status     string
+	// Endpoint is the API method, e.g. "torrents/properties".
+	Endpoint string
+	// Params holds the request parameters, passwords are redacted.
+	Params url.Values
+	// Body is the response body, which usually explains the failure.
+	Body string
+	// Err is the error describing the failure.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: %s: %s", e.Endpoint, ErrBadResponse, e.Status)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == ErrBadResponse || target == statusErr(e.StatusCode)
+}
+
+// maxErrBody limits how much of a response body is kept in an APIError.
+const maxErrBody = 4 << 10
+
+// newAPIError consumes and closes the body of resp.
+func newAPIError(resp *http.Response, endpoint string, params url.Values) *APIError {
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrBody))
+	ignrBody(resp.Body)
+	if endpoint == "" && resp.Request != nil {
+		p := resp.Request.URL.Path
+		if i := strings.Index(p, "api/v2/"); i != -1 {
+			p = p[i+len("api/v2/"):]
+		}
+		endpoint = p
+	}
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Endpoint:   endpoint,
+		Params:     params,
+		Body:       strings.TrimSpace(string(b)),
+		Err:        statusErr(resp.StatusCode),
+	}
+	// "Your IP address has been banned after too many failed authentication attempts."
+	if e.StatusCode == http.StatusForbidden && strings.Contains(strings.ToLower(e.Body), "banned") {
+		e.Err = ErrIPBanned
+	}
+	return e
+}
+
+// withStatusErr sets the Err of err to target if err is an *APIError with the
+// status code, unless it has a more specific error than the one of the code,
+// e.g. ErrIPBanned.
+func withStatusErr(err error, code int, target error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == code && apiErr.Err == statusErr(code) {
+		apiErr.Err = target
+	}
+	return err
+}
+
+// redactedParams converts opts to url.Values, hiding the values of passwords.
+func redactedParams(opts Optional) url.Values {
+	values := url.Values{}
+	for k, v := range opts.StringField() {
+		if strings.Contains(strings.ToLower(k), "password") {
+			v = "REDACTED"
+		}
+		values.Set(k, v)
+	}
+	return values
+}
@@ -0,0 +1,46 @@
+package qbt_apiv2
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle("torrents/properties", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Torrent hash was not found", http.StatusNotFound)
+	})
+	cli := srv.client(t)
+
+	_, err := cli.GetTorrentProperties("abc")
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, ErrBadResponse) || errors.Is(err, ErrConflict) {
+		t.Fatalf("unexpected error chain: %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("%T is not an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound ||
+		apiErr.Endpoint != "torrents/properties" ||
+		apiErr.Params.Get("hash") != "abc" ||
+		apiErr.Body != "Torrent hash was not found" {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+}
+
+func TestAPIErrorRedactsPassword(t *testing.T) {
+	srv := newFakeServer(t)
+	cli := srv.client(t)
+	srv.handle("auth/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	})
+	err := cli.Login("admin", "secret")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrForbidden) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if apiErr.Params.Get("username") != "admin" || apiErr.Params.Get("password") == "secret" {
+		t.Errorf("params not redacted: %v", apiErr.Params)
+	}
+}
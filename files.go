@@ -0,0 +1,201 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FilePriority is the download priority of a file of a torrent
+type FilePriority int
+
+const (
+	FilePrioDoNotDownload FilePriority = 0
+	FilePrioNormal        FilePriority = 1
+	FilePrioHigh          FilePriority = 6
+	FilePrioMaximum       FilePriority = 7
+)
+
+func (p FilePriority) String() string {
+	switch p {
+	case FilePrioDoNotDownload:
+		return "Do not download"
+	case FilePrioNormal:
+		return "Normal"
+	case FilePrioHigh:
+		return "High"
+	case FilePrioMaximum:
+		return "Maximum"
+	default:
+		return "Unknown"
+	}
+}
+
+// SetFilePriority sets the priority of the files of a torrent by their index.
+// It fails with ErrBadRequest if prio is invalid and with ErrConflict
+// if an index is out of range or the torrent metadata is not downloaded yet.
+func (c *Client) SetFilePriority(hash string, prio FilePriority, indexes ...int) error {
+	return c.SetFilePriorityContext(context.Background(), hash, prio, indexes...)
+}
+
+// SetFilePriorityContext is like SetFilePriority but uses ctx for the request.
+func (c *Client) SetFilePriorityContext(ctx context.Context, hash string, prio FilePriority, indexes ...int) error {
+	ids := make([]string, len(indexes))
+	for i, idx := range indexes {
+		ids[i] = strconv.Itoa(idx)
+	}
+	return c.postAction(ctx, "torrents/filePrio", Optional{
+		"hash":     hash,
+		"id":       strings.Join(ids, "|"),
+		"priority": int(prio),
+	})
+}
+
+// FileMatcher reports whether a file of a torrent is selected.
+type FileMatcher func(f File) bool
+
+// MatchGlob matches the files whose path matches the shell pattern,
+// see path.Match. A pattern without a slash is matched against the file name only.
+func MatchGlob(pattern string) (FileMatcher, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	base := !strings.Contains(pattern, "/")
+	return func(f File) bool {
+		name := f.Name
+		if base {
+			name = path.Base(name)
+		}
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}, nil
+}
+
+// MatchRegexp matches the files whose path matches re.
+func MatchRegexp(re *regexp.Regexp) FileMatcher {
+	return func(f File) bool {
+		return re.MatchString(f.Name)
+	}
+}
+
+// MatchExt matches the files with one of the extensions, ignoring case,
+// e.g. MatchExt("mkv", ".mp4").
+func MatchExt(exts ...string) FileMatcher {
+	norm := make([]string, len(exts))
+	for i, e := range exts {
+		norm[i] = "." + strings.ToLower(strings.TrimPrefix(e, "."))
+	}
+	return func(f File) bool {
+		return containsStr(norm, strings.ToLower(path.Ext(f.Name)))
+	}
+}
+
+// MatchSize matches the files whose size in bytes is between min and max,
+// both inclusive, max <= 0 means no upper bound.
+func MatchSize(min, max int) FileMatcher {
+	return func(f File) bool {
+		return f.Size >= min && (max <= 0 || f.Size <= max)
+	}
+}
+
+// MatchAll matches the files matched by every matcher.
+func MatchAll(ms ...FileMatcher) FileMatcher {
+	return func(f File) bool {
+		for _, m := range ms {
+			if !m(f) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchAny matches the files matched by at least one matcher.
+func MatchAny(ms ...FileMatcher) FileMatcher {
+	return func(f File) bool {
+		for _, m := range ms {
+			if m(f) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// SelectFiles sets the priority of the files of a torrent matched by match
+// and returns their number, the other files are left untouched.
+func (c *Client) SelectFiles(hash string, match FileMatcher, prio FilePriority) (int, error) {
+	return c.SelectFilesContext(context.Background(), hash, match, prio)
+}
+
+// SelectFilesContext is like SelectFiles but uses ctx for the requests.
+func (c *Client) SelectFilesContext(ctx context.Context, hash string, match FileMatcher, prio FilePriority) (int, error) {
+	fs, err := c.FilesContext(ctx, hash)
+	if err != nil {
+		return 0, err
+	}
+	// the position is the index of the file in the full list,
+	// servers older than web API v2.8.2 do not send `index`
+	var idxs []int
+	for i, f := range fs {
+		if match(f) {
+			idxs = append(idxs, i)
+		}
+	}
+	if len(idxs) == 0 {
+		return 0, nil
+	}
+	err = c.SetFilePriorityContext(ctx, hash, prio, idxs...)
+	if err != nil {
+		return 0, err
+	}
+	return len(idxs), nil
+}
+
+// DownloadOnly downloads only the files of a torrent matched by match.
+// The matched files which were not downloaded are set to normal priority,
+// the others keep their priority, and every other file is not downloaded.
+// It fails with ErrNoFileMatched, without changing anything, if no file matches.
+func (c *Client) DownloadOnly(hash string, match FileMatcher) error {
+	return c.DownloadOnlyContext(context.Background(), hash, match)
+}
+
+// DownloadOnlyContext is like DownloadOnly but uses ctx for the requests.
+func (c *Client) DownloadOnlyContext(ctx context.Context, hash string, match FileMatcher) error {
+	fs, err := c.FilesContext(ctx, hash)
+	if err != nil {
+		return err
+	}
+	var (
+		matched bool
+		enable  []int
+		disable []int
+	)
+	for i, f := range fs {
+		switch {
+		case match(f):
+			matched = true
+			if f.Priority == FilePrioDoNotDownload {
+				enable = append(enable, i)
+			}
+		case f.Priority != FilePrioDoNotDownload:
+			disable = append(disable, i)
+		}
+	}
+	if !matched {
+		return ErrNoFileMatched
+	}
+	// enable first, so the torrent never has every file deselected
+	if len(enable) > 0 {
+		err = c.SetFilePriorityContext(ctx, hash, FilePrioNormal, enable...)
+		if err != nil {
+			return err
+		}
+	}
+	if len(disable) > 0 {
+		return c.SetFilePriorityContext(ctx, hash, FilePrioDoNotDownload, disable...)
+	}
+	return nil
+}
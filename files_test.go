@@ -0,0 +1,75 @@
+package qbt_apiv2
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+const filesJSON = `[
+	{"index":0,"name":"Show/S01E01.mkv","size":700,"priority":1},
+	{"index":1,"name":"Show/S01E02.MKV","size":800,"priority":0},
+	{"index":2,"name":"Show/sample/S01E01.sample.mkv","size":20,"priority":1},
+	{"index":3,"name":"Show/info.nfo","size":1,"priority":6}]`
+
+func TestDownloadOnly(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("torrents/files", filesJSON)
+	var prios []string
+	srv.handle("torrents/filePrio", func(w http.ResponseWriter, r *http.Request) {
+		prios = append(prios, r.FormValue("priority")+":"+r.FormValue("id"))
+	})
+	cli := srv.client(t)
+
+	err := cli.DownloadOnly("aaa", MatchAll(MatchExt("mkv"), MatchSize(100, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(prios) != "[1:1 0:2|3]" {
+		t.Errorf("filePrio calls = %v", prios)
+	}
+
+	prios = nil
+	glob, err := MatchGlob("*.nfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := cli.SelectFiles("aaa", glob, FilePrioMaximum)
+	if n != 1 || err != nil || fmt.Sprint(prios) != "[7:3]" {
+		t.Errorf("SelectFiles = %d, %v, calls %v", n, err, prios)
+	}
+
+	prios = nil
+	err = cli.DownloadOnly("aaa", MatchExt("iso"))
+	if !errors.Is(err, ErrNoFileMatched) || prios != nil {
+		t.Errorf("DownloadOnly without match = %v, calls %v", err, prios)
+	}
+}
+
+func TestSelectFilesWithoutIndex(t *testing.T) {
+	srv := newFakeServer(t)
+	// servers older than web API v2.8.2 do not send `index`
+	srv.reply("torrents/files", `[
+		{"name":"a.mkv","size":700,"priority":1},
+		{"name":"b.nfo","size":1,"priority":1}]`)
+	var ids string
+	srv.handle("torrents/filePrio", func(w http.ResponseWriter, r *http.Request) {
+		ids = r.FormValue("id")
+	})
+	cli := srv.client(t)
+	n, err := cli.SelectFiles("aaa", MatchExt("nfo"), FilePrioDoNotDownload)
+	if n != 1 || err != nil || ids != "1" {
+		t.Errorf("SelectFiles = %d, %v, id %q", n, err, ids)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	if _, err := MatchGlob("[a-"); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+	m, _ := MatchGlob("Show/sample/*")
+	if !m(File{Name: "Show/sample/S01E01.sample.mkv"}) || m(File{Name: "Show/S01E01.mkv"}) {
+		t.Error("path pattern mismatch")
+	}
+}
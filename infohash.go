@@ -0,0 +1,106 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/NullpointerW/go-qbittorrent-apiv2/magnet"
+	"github.com/NullpointerW/go-qbittorrent-apiv2/metainfo"
+)
+
+// InfoHash holds the hex encoded info-hashes of a torrent,
+// V1 (SHA-1) and V2 (SHA-256) are both set for hybrid torrents.
+type InfoHash struct {
+	V1 string
+	V2 string
+}
+
+// ID returns the hash qBittorrent identifies the torrent with, see metainfo.ID.
+func (h InfoHash) ID() string {
+	return metainfo.ID(h.V1, h.V2)
+}
+
+// TorrentInfoHash computes the info-hashes of the content of a .torrent file.
+func TorrentInfoHash(b []byte) (InfoHash, error) {
+	mi, err := metainfo.Parse(b)
+	if err != nil {
+		return InfoHash{}, err
+	}
+	return InfoHash{V1: mi.InfoHashV1(), V2: mi.InfoHashV2()}, nil
+}
+
+// MagnetInfoHash parses the info-hashes of a magnet link.
+func MagnetInfoHash(uri string) (InfoHash, error) {
+	m, err := magnet.Parse(uri)
+	if err != nil {
+		return InfoHash{}, err
+	}
+	return InfoHash{V1: m.InfoHashV1, V2: m.InfoHashV2}, nil
+}
+
+// Magnet returns the magnet link of t, parsed from MagnetURI if the server sent it,
+// or else built from its info-hashes, name and current tracker.
+// See Client.TorrentMagnet for a link with every tracker and web seed.
+func (t Torrent) Magnet() (magnet.Magnet, error) {
+	if t.MagnetURI != "" {
+		return magnet.Parse(t.MagnetURI)
+	}
+	m := magnet.Magnet{
+		InfoHashV1: strings.ToLower(t.InfohashV1),
+		InfoHashV2: strings.ToLower(t.InfohashV2),
+		Name:       t.Name,
+	}
+	// servers older than qBittorrent v4.4.0 only send the v1 info-hash as Hash
+	if m.InfoHashV1 == "" && m.InfoHashV2 == "" && len(t.Hash) == 40 {
+		m.InfoHashV1 = strings.ToLower(t.Hash)
+	}
+	if m.InfoHashV1 == "" && m.InfoHashV2 == "" {
+		return magnet.Magnet{}, fmt.Errorf("%w: torrent %q has no info-hash", magnet.ErrInvalid, t.Name)
+	}
+	if t.Tracker != "" {
+		m.Trackers = []string{t.Tracker}
+	}
+	return m, nil
+}
+
+// TorrentMagnet builds the magnet link of a torrent with its length,
+// every tracker and web seed.
+func (c *Client) TorrentMagnet(hash string) (magnet.Magnet, error) {
+	return c.TorrentMagnetContext(context.Background(), hash)
+}
+
+// TorrentMagnetContext is like TorrentMagnet but uses ctx for the requests.
+func (c *Client) TorrentMagnetContext(ctx context.Context, hash string) (magnet.Magnet, error) {
+	t, ok, err := c.findTorrent(ctx, hash)
+	if err != nil {
+		return magnet.Magnet{}, err
+	}
+	if !ok {
+		return magnet.Magnet{}, fmt.Errorf("%w: torrent %s", ErrNotFound, hash)
+	}
+	m, err := t.Magnet()
+	if err != nil {
+		return magnet.Magnet{}, err
+	}
+	m.Length = int64(t.TotalSize)
+	trackers, err := c.GetTorrentTrackersContext(ctx, t.Hash)
+	if err != nil {
+		return magnet.Magnet{}, err
+	}
+	m.Trackers = nil
+	for _, tr := range trackers {
+		if !tr.IsSpecial() {
+			m.Trackers = append(m.Trackers, tr.URL)
+		}
+	}
+	webSeeds, err := c.GetWebSeedsContext(ctx, t.Hash)
+	if err != nil {
+		return magnet.Magnet{}, err
+	}
+	m.WebSeeds = nil
+	for _, ws := range webSeeds {
+		m.WebSeeds = append(m.WebSeeds, ws.URL)
+	}
+	return m, nil
+}
@@ -0,0 +1,99 @@
+package qbt_apiv2
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestTorrentInfoHash(t *testing.T) {
+	const (
+		v1Info     = "d6:lengthi3e4:name5:a.txt12:piece lengthi16384e6:pieces20:aaaaaaaaaaaaaaaaaaaae"
+		v2Info     = "d9:file treed5:a.txtd0:d6:lengthi3eeee12:meta versioni2e4:name5:a.txt12:piece lengthi16384ee"
+		hybridInfo = "d9:file treed5:a.txtd0:d6:lengthi3eeee6:lengthi3e12:meta versioni2e4:name5:a.txt12:piece lengthi16384e6:pieces20:aaaaaaaaaaaaaaaaaaaae"
+	)
+	sha1Hex := func(s string) string {
+		sum := sha1.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	sha256Hex := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	for _, tt := range []struct {
+		torrent string
+		want    InfoHash
+	}{
+		{"d8:announce15:http://t.ex/ann4:info" + v1Info + "e", InfoHash{V1: sha1Hex(v1Info)}},
+		{"d4:info" + v2Info + "12:piece layersdee", InfoHash{V2: sha256Hex(v2Info)}},
+		{"d4:info" + hybridInfo + "e", InfoHash{V1: sha1Hex(hybridInfo), V2: sha256Hex(hybridInfo)}},
+	} {
+		h, err := TorrentInfoHash([]byte(tt.torrent))
+		if err != nil || h != tt.want {
+			t.Errorf("TorrentInfoHash(%q) = %+v, %v, want %+v", tt.torrent, h, err, tt.want)
+		}
+	}
+	if h := (InfoHash{V2: sha256Hex(v2Info)}); h.ID() != h.V2[:40] {
+		t.Errorf("ID of a v2 torrent = %s", h.ID())
+	}
+	for _, bad := range []string{"", "d4:info", "d4:infoi1ee", "d4:info5:abcee", "l4:infoe"} {
+		if _, err := TorrentInfoHash([]byte(bad)); err == nil {
+			t.Errorf("TorrentInfoHash(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestMagnetInfoHash(t *testing.T) {
+	const v1 = "c9e15763f722f23e98a29decdfae341b98d53056"
+	for _, uri := range []string{
+		"magnet:?xt=urn:btih:" + v1 + "&dn=x",
+		"magnet:?xt=urn:btih:ZHQVOY7XELZD5GFCTXWN7LRUDOMNKMCW",
+		"magnet:?dn=x&xt=urn:btih:C9E15763F722F23E98A29DECDFAE341B98D53056",
+	} {
+		h, err := MagnetInfoHash(uri)
+		if err != nil || h.V1 != v1 || h.ID() != v1 {
+			t.Errorf("MagnetInfoHash(%q) = %+v, %v", uri, h, err)
+		}
+	}
+	v2 := "1220" + "d8dd32ac93357c368556af3ac1d95c9d76bd0dff6fa9833ecdac3d53134efabb"
+	h, err := MagnetInfoHash("magnet:?xt=urn:btmh:" + v2)
+	if err != nil || h.V2 != v2[4:] || h.ID() != v2[4:44] {
+		t.Errorf("MagnetInfoHash(btmh) = %+v, %v", h, err)
+	}
+	for _, bad := range []string{"http://x", "magnet:?dn=x", "magnet:?xt=urn:btih:abc"} {
+		if _, err := MagnetInfoHash(bad); err == nil {
+			t.Errorf("MagnetInfoHash(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestTorrentMagnet(t *testing.T) {
+	const hash = "c9e15763f722f23e98a29decdfae341b98d53056"
+	srv := newFakeServer(t)
+	srv.reply("torrents/info", `[{"hash":"`+hash+`","infohash_v1":"`+hash+`","name":"Big Buck Bunny","total_size":276445467,
+		"tracker":"udp://a.example.org:1337"}]`)
+	srv.reply("torrents/trackers", `[{"url":"** [DHT] **","tier":""},
+		{"url":"udp://a.example.org:1337","tier":0},{"url":"https://b.example.org/announce","tier":1}]`)
+	srv.reply("torrents/webseeds", `[{"url":"https://mirror.example.org/"}]`)
+	cli := srv.client(t)
+	m, err := cli.TorrentMagnet(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "magnet:?xt=urn:btih:" + hash + "&dn=Big+Buck+Bunny&xl=276445467" +
+		"&tr=udp%3A%2F%2Fa.example.org%3A1337&tr=https%3A%2F%2Fb.example.org%2Fannounce&ws=https%3A%2F%2Fmirror.example.org%2F"
+	if m.String() != want || m.ID() != hash {
+		t.Errorf("TorrentMagnet = %s", m)
+	}
+
+	// the link sent by the server is preferred
+	tr := Torrent{Hash: hash, Name: "x", MagnetURI: "magnet:?xt=urn:btih:" + strings.ToUpper(hash) + "&dn=y"}
+	if m, err = tr.Magnet(); err != nil || m.Name != "y" || m.InfoHashV1 != hash {
+		t.Errorf("Magnet = %+v, %v", m, err)
+	}
+	if _, err = (Torrent{Name: "x"}).Magnet(); err == nil {
+		t.Error("built a magnet link without info-hash")
+	}
+}
@@ -0,0 +1,138 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"strings"
+)
+
+// SpeedLimit is a speed limit of a torrent in bytes/s.
+type SpeedLimit int
+
+// SpeedUnlimited removes the speed limit of a torrent.
+const SpeedUnlimited SpeedLimit = 0
+
+// RatioLimit is the share ratio after which a torrent stops seeding.
+type RatioLimit float64
+
+// Special values of RatioLimit.
+const (
+	RatioGlobal    RatioLimit = -2 // use the global ratio limit of the preferences
+	RatioUnlimited RatioLimit = -1 // no ratio limit
+)
+
+// TimeLimit is a seeding time in minutes after which a torrent stops seeding.
+type TimeLimit int
+
+// Special values of TimeLimit.
+const (
+	TimeGlobal    TimeLimit = -2 // use the global time limit of the preferences
+	TimeUnlimited TimeLimit = -1 // no time limit
+)
+
+// ShareLimits are the limits after which a torrent stops seeding.
+type ShareLimits struct {
+	RatioLimit       RatioLimit
+	SeedingTimeLimit TimeLimit
+	// InactiveSeedingTimeLimit is qBittorrent v4.6.0+.
+	InactiveSeedingTimeLimit TimeLimit
+}
+
+// ShareLimits returns the share limits of t.
+func (t Torrent) ShareLimits() ShareLimits {
+	return ShareLimits{
+		RatioLimit:               RatioLimit(t.RatioLimit),
+		SeedingTimeLimit:         TimeLimit(t.SeedingTimeLimit),
+		InactiveSeedingTimeLimit: TimeLimit(t.InactiveSeedingTimeLimit),
+	}
+}
+
+// GetDownloadLimits returns the download speed limit in bytes/s of torrents by hash,
+// SpeedUnlimited if a torrent has none.
+func (c *Client) GetDownloadLimits(hashes ...string) (map[string]SpeedLimit, error) {
+	return c.GetDownloadLimitsContext(context.Background(), hashes...)
+}
+
+// GetDownloadLimitsContext is like GetDownloadLimits but uses ctx for the request.
+func (c *Client) GetDownloadLimitsContext(ctx context.Context, hashes ...string) (map[string]SpeedLimit, error) {
+	return c.getLimits(ctx, "torrents/downloadLimit", hashes)
+}
+
+// SetDownloadLimit sets the download speed limit in bytes/s of torrents,
+// SpeedUnlimited removes it.
+func (c *Client) SetDownloadLimit(limit SpeedLimit, hashes ...string) error {
+	return c.SetDownloadLimitContext(context.Background(), limit, hashes...)
+}
+
+// SetDownloadLimitContext is like SetDownloadLimit but uses ctx for the request.
+func (c *Client) SetDownloadLimitContext(ctx context.Context, limit SpeedLimit, hashes ...string) error {
+	return c.postAction(ctx, "torrents/setDownloadLimit", Optional{
+		"hashes": strings.Join(hashes, "|"),
+		"limit":  limit,
+	})
+}
+
+// GetUploadLimits returns the upload speed limit in bytes/s of torrents by hash,
+// SpeedUnlimited if a torrent has none.
+func (c *Client) GetUploadLimits(hashes ...string) (map[string]SpeedLimit, error) {
+	return c.GetUploadLimitsContext(context.Background(), hashes...)
+}
+
+// GetUploadLimitsContext is like GetUploadLimits but uses ctx for the request.
+func (c *Client) GetUploadLimitsContext(ctx context.Context, hashes ...string) (map[string]SpeedLimit, error) {
+	return c.getLimits(ctx, "torrents/uploadLimit", hashes)
+}
+
+// SetUploadLimit sets the upload speed limit in bytes/s of torrents,
+// SpeedUnlimited removes it.
+func (c *Client) SetUploadLimit(limit SpeedLimit, hashes ...string) error {
+	return c.SetUploadLimitContext(context.Background(), limit, hashes...)
+}
+
+// SetUploadLimitContext is like SetUploadLimit but uses ctx for the request.
+func (c *Client) SetUploadLimitContext(ctx context.Context, limit SpeedLimit, hashes ...string) error {
+	return c.postAction(ctx, "torrents/setUploadLimit", Optional{
+		"hashes": strings.Join(hashes, "|"),
+		"limit":  limit,
+	})
+}
+
+func (c *Client) getLimits(ctx context.Context, endpoint string, hashes []string) (map[string]SpeedLimit, error) {
+	var limits map[string]SpeedLimit
+	err := c.getJSON(ctx, endpoint, Optional{
+		"hashes": strings.Join(hashes, "|"),
+	}, &limits)
+	if err != nil {
+		return nil, err
+	}
+	// older servers report no limit as -1
+	for h, l := range limits {
+		if l < 0 {
+			limits[h] = SpeedUnlimited
+		}
+	}
+	return limits, nil
+}
+
+// SetShareLimits sets the share limits of torrents. Note the zero ShareLimits
+// stops seeding at once, use RatioGlobal and TimeGlobal to keep the global limits.
+// InactiveSeedingTimeLimit is not sent to servers older than qBittorrent v4.6.0.
+func (c *Client) SetShareLimits(limits ShareLimits, hashes ...string) error {
+	return c.SetShareLimitsContext(context.Background(), limits, hashes...)
+}
+
+// SetShareLimitsContext is like SetShareLimits but uses ctx for the request.
+func (c *Client) SetShareLimitsContext(ctx context.Context, limits ShareLimits, hashes ...string) error {
+	v, err := c.apiVersion(ctx)
+	if err != nil {
+		return err
+	}
+	opt := Optional{
+		"hashes":           strings.Join(hashes, "|"),
+		"ratioLimit":       limits.RatioLimit,
+		"seedingTimeLimit": limits.SeedingTimeLimit,
+	}
+	if v.atLeast(apiInactiveSeeding) {
+		opt["inactiveSeedingTimeLimit"] = limits.InactiveSeedingTimeLimit
+	}
+	return c.postAction(ctx, "torrents/setShareLimits", opt)
+}
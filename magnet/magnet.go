@@ -0,0 +1,199 @@
+// Package magnet parses and builds BitTorrent magnet links (BEP 9, BEP 53 and BEP 52).
+package magnet
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NullpointerW/go-qbittorrent-apiv2/metainfo"
+)
+
+// ErrInvalid is matched by the errors of malformed magnet links.
+var ErrInvalid = errors.New("invalid magnet link")
+
+// Magnet is a magnet link, its info-hashes are lowercase hex as in `Torrent.Hash`.
+type Magnet struct {
+	// InfoHashV1 is the SHA-1 info-hash of `xt=urn:btih:`.
+	InfoHashV1 string
+	// InfoHashV2 is the SHA-256 info-hash of `xt=urn:btmh:`.
+	InfoHashV2 string
+	// Name is the display name `dn`.
+	Name string
+	// Length is the exact length `xl` in bytes, 0 if unknown.
+	Length int64
+	// Trackers are the tracker urls `tr`.
+	Trackers []string
+	// WebSeeds are the web seed urls `ws`.
+	WebSeeds []string
+	// SelectOnly are the indexes of the files to download `so`, all if empty.
+	SelectOnly []int
+}
+
+const (
+	btih = "urn:btih:"
+	btmh = "urn:btmh:"
+	// multihash prefix of SHA-256, 0x12 for the function and 0x20 for the length
+	sha256Multihash = "1220"
+)
+
+// Parse parses a magnet link, which must have at least one info-hash.
+func Parse(uri string) (Magnet, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return Magnet{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if u.Scheme != "magnet" {
+		return Magnet{}, fmt.Errorf("%w: scheme %q", ErrInvalid, u.Scheme)
+	}
+	q, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return Magnet{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	var m Magnet
+	for _, xt := range q["xt"] {
+		switch {
+		case strings.HasPrefix(strings.ToLower(xt), btih):
+			if m.InfoHashV1, err = parseBTIH(xt[len(btih):]); err != nil {
+				return Magnet{}, err
+			}
+		case strings.HasPrefix(strings.ToLower(xt), btmh):
+			if m.InfoHashV2, err = parseBTMH(xt[len(btmh):]); err != nil {
+				return Magnet{}, err
+			}
+		}
+	}
+	if m.InfoHashV1 == "" && m.InfoHashV2 == "" {
+		return Magnet{}, fmt.Errorf("%w: no info-hash", ErrInvalid)
+	}
+	m.Name = q.Get("dn")
+	if xl := q.Get("xl"); xl != "" {
+		if m.Length, err = strconv.ParseInt(xl, 10, 64); err != nil || m.Length < 0 {
+			return Magnet{}, fmt.Errorf("%w: xl %q", ErrInvalid, xl)
+		}
+	}
+	m.Trackers = q["tr"]
+	m.WebSeeds = q["ws"]
+	if so := q.Get("so"); so != "" {
+		if m.SelectOnly, err = parseSelectOnly(so); err != nil {
+			return Magnet{}, err
+		}
+	}
+	return m, nil
+}
+
+// parseBTIH decodes a v1 info-hash in hex or base32.
+func parseBTIH(s string) (string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	switch len(s) {
+	case 40:
+		b, err = hex.DecodeString(s)
+	case 32:
+		b, err = base32.StdEncoding.DecodeString(strings.ToUpper(s))
+	default:
+		err = errors.New("length is neither 40 nor 32")
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: btih %q: %w", ErrInvalid, s, err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// parseBTMH decodes a v2 info-hash, a SHA-256 multihash in hex.
+func parseBTMH(s string) (string, error) {
+	s = strings.ToLower(s)
+	if len(s) != 68 || !strings.HasPrefix(s, sha256Multihash) {
+		return "", fmt.Errorf("%w: btmh %q is not a SHA-256 multihash", ErrInvalid, s)
+	}
+	if _, err := hex.DecodeString(s); err != nil {
+		return "", fmt.Errorf("%w: btmh %q: %w", ErrInvalid, s, err)
+	}
+	return s[len(sha256Multihash):], nil
+}
+
+// maxSelectOnly bounds the number of file indexes of `so`,
+// so a huge range of an untrusted link does not exhaust the memory.
+const maxSelectOnly = 1 << 20
+
+// parseSelectOnly parses a list of file indexes and ranges, e.g. "0,2,4-6".
+func parseSelectOnly(s string) ([]int, error) {
+	var idxs []int
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		a, err := strconv.Atoi(first)
+		b := a
+		if err == nil && isRange {
+			b, err = strconv.Atoi(last)
+		}
+		if err != nil || a < 0 || b < a {
+			return nil, fmt.Errorf("%w: so %q", ErrInvalid, s)
+		}
+		if b-a >= maxSelectOnly-len(idxs) {
+			return nil, fmt.Errorf("%w: so %q selects more than %d files", ErrInvalid, s, maxSelectOnly)
+		}
+		for i := a; i <= b; i++ {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs, nil
+}
+
+// ID returns the hash qBittorrent identifies the torrent with, see metainfo.ID.
+func (m Magnet) ID() string {
+	return metainfo.ID(m.InfoHashV1, m.InfoHashV2)
+}
+
+// String builds the magnet link.
+func (m Magnet) String() string {
+	var params []string
+	if m.InfoHashV1 != "" {
+		params = append(params, "xt="+btih+strings.ToLower(m.InfoHashV1))
+	}
+	if m.InfoHashV2 != "" {
+		params = append(params, "xt="+btmh+sha256Multihash+strings.ToLower(m.InfoHashV2))
+	}
+	if m.Name != "" {
+		params = append(params, "dn="+url.QueryEscape(m.Name))
+	}
+	if m.Length > 0 {
+		params = append(params, "xl="+strconv.FormatInt(m.Length, 10))
+	}
+	for _, tr := range m.Trackers {
+		params = append(params, "tr="+url.QueryEscape(tr))
+	}
+	for _, ws := range m.WebSeeds {
+		params = append(params, "ws="+url.QueryEscape(ws))
+	}
+	if len(m.SelectOnly) > 0 {
+		params = append(params, "so="+formatSelectOnly(m.SelectOnly))
+	}
+	return "magnet:?" + strings.Join(params, "&")
+}
+
+// formatSelectOnly joins idxs collapsing the consecutive ones into ranges.
+func formatSelectOnly(idxs []int) string {
+	s := append([]int(nil), idxs...)
+	sort.Ints(s)
+	var parts []string
+	for i := 0; i < len(s); {
+		j := i
+		for j+1 < len(s) && s[j+1] <= s[j]+1 {
+			j++
+		}
+		if s[j] == s[i] {
+			parts = append(parts, strconv.Itoa(s[i]))
+		} else {
+			parts = append(parts, strconv.Itoa(s[i])+"-"+strconv.Itoa(s[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,66 @@
+package magnet
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const (
+	v1 = "c9e15763f722f23e98a29decdfae341b98d53056"
+	v2 = "d8dd32ac93357c368556af3ac1d95c9d76bd0dff6fa9833ecdac3d53134efabb"
+)
+
+func TestParse(t *testing.T) {
+	m, err := Parse("magnet:?xt=urn:btih:ZHQVOY7XELZD5GFCTXWN7LRUDOMNKMCW&xt=urn:btmh:1220" + v2 +
+		"&dn=Big+Buck%20Bunny&xl=276445467&tr=udp%3A%2F%2Ftracker.example.org%3A1337&tr=wss://t.example.org" +
+		"&ws=https%3A%2F%2Fwebtorrent.io%2Ftorrents%2F&so=0,2,4-6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Magnet{
+		InfoHashV1: v1,
+		InfoHashV2: v2,
+		Name:       "Big Buck Bunny",
+		Length:     276445467,
+		Trackers:   []string{"udp://tracker.example.org:1337", "wss://t.example.org"},
+		WebSeeds:   []string{"https://webtorrent.io/torrents/"},
+		SelectOnly: []int{0, 2, 4, 5, 6},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("Parse = %+v", m)
+	}
+	if m.ID() != v1 || (Magnet{InfoHashV2: v2}).ID() != v2[:40] {
+		t.Errorf("ID = %s", m.ID())
+	}
+
+	// building and parsing again is lossless
+	again, err := Parse(m.String())
+	if err != nil || !reflect.DeepEqual(again, m) {
+		t.Errorf("Parse(%s) = %+v, %v", m.String(), again, err)
+	}
+	const built = "magnet:?xt=urn:btih:c9e15763f722f23e98a29decdfae341b98d53056&xt=urn:btmh:1220d8dd32ac93357c368556af3ac1d95c9d76bd0dff6fa9833ecdac3d53134efabb" +
+		"&dn=Big+Buck+Bunny&xl=276445467&tr=udp%3A%2F%2Ftracker.example.org%3A1337&tr=wss%3A%2F%2Ft.example.org&ws=https%3A%2F%2Fwebtorrent.io%2Ftorrents%2F&so=0,2,4-6"
+	if m.String() != built {
+		t.Errorf("String = %s", m.String())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, bad := range []string{
+		"http://example.org/?xt=urn:btih:" + v1,
+		"magnet:?dn=x",
+		"magnet:?xt=urn:btih:abc",
+		"magnet:?xt=urn:btih:" + v1[:39] + "z",
+		"magnet:?xt=urn:btmh:1114" + v2,
+		"magnet:?xt=urn:btih:" + v1 + "&xl=-1",
+		"magnet:?xt=urn:btih:" + v1 + "&so=3-1",
+		"magnet:?xt=urn:btih:" + v1 + "&so=0-2000000000",
+		"magnet:?xt=urn:btih:" + v1 + "&so=0-9223372036854775807",
+		"magnet:?xt=urn:btih:" + v1 + "&so=0-600000,700000-1400000",
+	} {
+		if _, err := Parse(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) = %v, want ErrInvalid", bad, err)
+		}
+	}
+}
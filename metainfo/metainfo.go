@@ -0,0 +1,398 @@
+// Package metainfo parses .torrent files (BEP 3), including the v2 and hybrid
+// layouts (BEP 52), and computes their info-hashes.
+package metainfo
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NullpointerW/go-qbittorrent-apiv2/bencode"
+)
+
+// ErrInvalid is matched by the errors of malformed metainfo.
+var ErrInvalid = errors.New("invalid metainfo")
+
+// MetaInfo is the content of a .torrent file.
+//
+// InfoBytes is the encoded info dictionary which the info-hashes are computed
+// from and Info is decoded from. A MetaInfo built by hand may only set Info,
+// InfoBytes is then encoded from it, which only gives the info-hashes of the
+// original torrent if it was encoded canonically without unknown keys.
+type MetaInfo struct {
+	Announce     string             `bencode:"announce,omitempty"`
+	AnnounceList [][]string         `bencode:"announce-list,omitempty"`
+	Comment      string             `bencode:"comment,omitempty"`
+	CreatedBy    string             `bencode:"created by,omitempty"`
+	CreationDate int64              `bencode:"creation date,omitempty"`
+	Encoding     string             `bencode:"encoding,omitempty"`
+	URLList      StringList         `bencode:"url-list,omitempty"`
+	Info         Info               `bencode:"-"`
+	InfoBytes    bencode.RawMessage `bencode:"info"`
+
+	raw []byte
+}
+
+// Info is the info dictionary of a .torrent file.
+type Info struct {
+	Name        string
+	PieceLength int64
+	// Pieces holds the SHA-1 hashes of the pieces, v1 only.
+	Pieces  []byte
+	Private bool
+	// Length is the length of the file of a single-file v1 torrent.
+	Length int64
+	// Files are the files of a multi-file v1 torrent, including padding files.
+	Files []FileV1
+	// MetaVersion is 2 for v2 and hybrid torrents.
+	MetaVersion int
+	// FileTree holds the files of the `file tree` of v2 and hybrid torrents.
+	FileTree []FileV2
+}
+
+// FileV1 is a file of a multi-file v1 torrent.
+type FileV1 struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+	// Attr holds the BEP 47 attributes, "p" for padding files.
+	Attr string `bencode:"attr,omitempty"`
+}
+
+// FileV2 is a file of the `file tree` of a v2 torrent.
+type FileV2 struct {
+	Path   []string
+	Length int64
+	// PiecesRoot is the root hash of the merkle tree of the file, empty for empty files.
+	PiecesRoot []byte
+}
+
+// File is a file of a torrent, its Path starts with the name of the torrent
+// for multi-file torrents.
+type File struct {
+	Path   string
+	Length int64
+}
+
+// StringList is a list of strings which is also decoded from a single string,
+// as `url-list` may be.
+type StringList []string
+
+func (l *StringList) UnmarshalBencode(b []byte) error {
+	if len(b) > 0 && b[0] == 'l' {
+		return bencode.Unmarshal(b, (*[]string)(l))
+	}
+	var s string
+	if err := bencode.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*l = StringList{s}
+	return nil
+}
+
+// infoDict is the info dictionary encoded from Info.
+type infoDict struct {
+	Name        string         `bencode:"name"`
+	PieceLength int64          `bencode:"piece length"`
+	Pieces      []byte         `bencode:"pieces,omitempty"`
+	Private     bool           `bencode:"private,omitempty"`
+	Length      int64          `bencode:"length,omitempty"`
+	Files       []FileV1       `bencode:"files,omitempty"`
+	MetaVersion int            `bencode:"meta version,omitempty"`
+	FileTree    map[string]any `bencode:"file tree,omitempty"`
+}
+
+// rawInfo is the info dictionary before the file tree is flattened.
+type rawInfo struct {
+	Name        string   `bencode:"name"`
+	PieceLength int64    `bencode:"piece length"`
+	Pieces      []byte   `bencode:"pieces"`
+	Private     bool     `bencode:"private"`
+	Length      int64    `bencode:"length"`
+	Files       []FileV1 `bencode:"files"`
+	MetaVersion int      `bencode:"meta version"`
+	FileTree    any      `bencode:"file tree"`
+}
+
+// Parse parses the content of a .torrent file.
+func Parse(b []byte) (*MetaInfo, error) {
+	mi := new(MetaInfo)
+	if err := bencode.Unmarshal(b, mi); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if len(mi.InfoBytes) == 0 {
+		return nil, fmt.Errorf("%w: no info dictionary", ErrInvalid)
+	}
+	var ri rawInfo
+	if err := bencode.Unmarshal(mi.InfoBytes, &ri); err != nil {
+		return nil, fmt.Errorf("%w: info: %w", ErrInvalid, err)
+	}
+	mi.Info = Info{
+		Name:        ri.Name,
+		PieceLength: ri.PieceLength,
+		Pieces:      ri.Pieces,
+		Private:     ri.Private,
+		Length:      ri.Length,
+		Files:       ri.Files,
+		MetaVersion: ri.MetaVersion,
+	}
+	if ri.FileTree != nil {
+		tree, ok := ri.FileTree.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: file tree is not a dictionary", ErrInvalid)
+		}
+		if err := flattenTree(tree, nil, &mi.Info.FileTree); err != nil {
+			return nil, err
+		}
+	}
+	if err := mi.validate(); err != nil {
+		return nil, err
+	}
+	mi.raw = b
+	return mi, nil
+}
+
+// Load parses the .torrent file at name.
+func Load(name string) (*MetaInfo, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+func (mi *MetaInfo) validate() error {
+	info := &mi.Info
+	switch {
+	case info.Name == "":
+		return fmt.Errorf("%w: no name", ErrInvalid)
+	case info.PieceLength <= 0:
+		return fmt.Errorf("%w: invalid piece length %d", ErrInvalid, info.PieceLength)
+	case !mi.IsV1() && !mi.IsV2():
+		return fmt.Errorf("%w: neither pieces nor meta version 2", ErrInvalid)
+	case mi.IsV1() && len(info.Pieces)%sha1.Size != 0:
+		return fmt.Errorf("%w: pieces length %d is not a multiple of 20", ErrInvalid, len(info.Pieces))
+	case mi.IsV2() && len(info.FileTree) == 0:
+		return fmt.Errorf("%w: no file tree", ErrInvalid)
+	}
+	return nil
+}
+
+// flattenTree appends the files of the v2 file tree to files in path order.
+func flattenTree(tree map[string]any, dir []string, files *[]FileV2) error {
+	names := make([]string, 0, len(tree))
+	for name := range tree {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		node, ok := tree[name].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: file tree entry %q is not a dictionary", ErrInvalid, name)
+		}
+		p := append(append([]string(nil), dir...), name)
+		leaf, ok := node[""].(map[string]any)
+		if !ok {
+			if err := flattenTree(node, p, files); err != nil {
+				return err
+			}
+			continue
+		}
+		length, ok := leaf["length"].(int64)
+		if !ok || length < 0 {
+			return fmt.Errorf("%w: invalid length of %s", ErrInvalid, strings.Join(p, "/"))
+		}
+		root, _ := leaf["pieces root"].(string)
+		*files = append(*files, FileV2{Path: p, Length: length, PiecesRoot: []byte(root)})
+	}
+	return nil
+}
+
+// infoBytes returns InfoBytes, or the encoding of Info if it is empty.
+func (mi *MetaInfo) infoBytes() []byte {
+	if len(mi.InfoBytes) > 0 {
+		return mi.InfoBytes
+	}
+	info := &mi.Info
+	d := infoDict{
+		Name:        info.Name,
+		PieceLength: info.PieceLength,
+		Pieces:      info.Pieces,
+		Private:     info.Private,
+		Length:      info.Length,
+		Files:       info.Files,
+		MetaVersion: info.MetaVersion,
+	}
+	if len(info.FileTree) > 0 {
+		d.FileTree = buildTree(info.FileTree)
+	}
+	// the info dictionary only holds types which are encoded without error
+	b, _ := bencode.Marshal(d)
+	return b
+}
+
+// buildTree is the reverse of flattenTree.
+func buildTree(files []FileV2) map[string]any {
+	tree := make(map[string]any)
+	for _, f := range files {
+		if len(f.Path) == 0 {
+			continue
+		}
+		dir := tree
+		for _, name := range f.Path[:len(f.Path)-1] {
+			sub, ok := dir[name].(map[string]any)
+			if !ok {
+				sub = make(map[string]any)
+				dir[name] = sub
+			}
+			dir = sub
+		}
+		leaf := map[string]any{"length": f.Length}
+		if len(f.PiecesRoot) > 0 {
+			leaf["pieces root"] = f.PiecesRoot
+		}
+		dir[f.Path[len(f.Path)-1]] = map[string]any{"": leaf}
+	}
+	return tree
+}
+
+// IsV1 reports whether the torrent has v1 metadata, which hybrid torrents have.
+func (mi *MetaInfo) IsV1() bool {
+	return len(mi.Info.Pieces) > 0
+}
+
+// IsV2 reports whether the torrent has v2 metadata, which hybrid torrents have.
+func (mi *MetaInfo) IsV2() bool {
+	return mi.Info.MetaVersion == 2
+}
+
+// IsHybrid reports whether the torrent has both v1 and v2 metadata.
+func (mi *MetaInfo) IsHybrid() bool {
+	return mi.IsV1() && mi.IsV2()
+}
+
+// InfoHashV1 returns the hex encoded SHA-1 info-hash, empty for v2-only torrents.
+func (mi *MetaInfo) InfoHashV1() string {
+	if !mi.IsV1() {
+		return ""
+	}
+	sum := sha1.Sum(mi.infoBytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// InfoHashV2 returns the hex encoded SHA-256 info-hash, empty for v1-only torrents.
+func (mi *MetaInfo) InfoHashV2() string {
+	if !mi.IsV2() {
+		return ""
+	}
+	sum := sha256.Sum256(mi.infoBytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// ID returns the hash qBittorrent identifies the torrent with, see ID.
+func (mi *MetaInfo) ID() string {
+	return ID(mi.InfoHashV1(), mi.InfoHashV2())
+}
+
+// ID returns the hash qBittorrent identifies a torrent with from its hex encoded
+// info-hashes, the v1 info-hash, or the v2 info-hash truncated to 20 bytes
+// for v2-only torrents.
+func ID(infoHashV1, infoHashV2 string) string {
+	if infoHashV1 != "" || len(infoHashV2) < 2*sha1.Size {
+		return infoHashV1
+	}
+	return infoHashV2[:2*sha1.Size]
+}
+
+// Files returns the files of the torrent without the padding files.
+func (mi *MetaInfo) Files() []File {
+	info := &mi.Info
+	var fs []File
+	switch {
+	case mi.IsV2():
+		single := len(info.FileTree) == 1 && len(info.FileTree[0].Path) == 1
+		for _, f := range info.FileTree {
+			p := path.Join(f.Path...)
+			if !single {
+				p = path.Join(info.Name, p)
+			}
+			fs = append(fs, File{Path: p, Length: f.Length})
+		}
+	case len(info.Files) > 0:
+		for _, f := range info.Files {
+			if strings.Contains(f.Attr, "p") {
+				continue
+			}
+			fs = append(fs, File{Path: path.Join(append([]string{info.Name}, f.Path...)...), Length: f.Length})
+		}
+	default:
+		fs = append(fs, File{Path: info.Name, Length: info.Length})
+	}
+	return fs
+}
+
+// TotalLength returns the length of the files without the padding files.
+func (mi *MetaInfo) TotalLength() int64 {
+	var n int64
+	for _, f := range mi.Files() {
+		n += f.Length
+	}
+	return n
+}
+
+// NumPieces returns the number of pieces, v2 pieces are aligned to the files.
+func (mi *MetaInfo) NumPieces() int {
+	if mi.IsV1() {
+		return len(mi.Info.Pieces) / sha1.Size
+	}
+	n := 0
+	for _, f := range mi.Info.FileTree {
+		n += int((f.Length + mi.Info.PieceLength - 1) / mi.Info.PieceLength)
+	}
+	return n
+}
+
+// Trackers returns the tiers of trackers, the `announce-list` if present
+// or else `announce`.
+func (mi *MetaInfo) Trackers() [][]string {
+	if len(mi.AnnounceList) > 0 {
+		return mi.AnnounceList
+	}
+	if mi.Announce != "" {
+		return [][]string{{mi.Announce}}
+	}
+	return nil
+}
+
+// WebSeeds returns the web seeds (BEP 19) of the torrent.
+func (mi *MetaInfo) WebSeeds() []string {
+	return mi.URLList
+}
+
+// CreationTime returns the creation date, the zero time if it is not set.
+func (mi *MetaInfo) CreationTime() time.Time {
+	if mi.CreationDate == 0 {
+		return time.Time{}
+	}
+	return time.Unix(mi.CreationDate, 0)
+}
+
+// Bytes returns the content of the .torrent file, the one mi was parsed from,
+// or the encoding of mi if it was built by hand, which fails if it is invalid.
+func (mi *MetaInfo) Bytes() ([]byte, error) {
+	if mi.raw != nil {
+		return mi.raw, nil
+	}
+	if err := mi.validate(); err != nil {
+		return nil, err
+	}
+	m := *mi
+	m.InfoBytes = mi.infoBytes()
+	return bencode.Marshal(&m)
+}
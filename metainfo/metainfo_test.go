@@ -0,0 +1,129 @@
+package metainfo
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	for _, tt := range []struct {
+		file     string
+		v1, v2   string
+		id       string
+		files    string
+		pieces   int
+		private  bool
+		trackers string
+		webSeeds string
+	}{
+		{
+			file:     "single",
+			v1:       "fcc009279110bdbda2cc4355f2ae0279e14f5466",
+			id:       "fcc009279110bdbda2cc4355f2ae0279e14f5466",
+			files:    "[{debian.iso 40000}]",
+			pieces:   3,
+			trackers: "[[http://tracker.example.org/announce]]",
+			webSeeds: "[https://mirror.example.org/debian.iso]",
+		},
+		{
+			file:     "multi",
+			v1:       "1fbe0a408b347695be02ec74fcc97c9ca7cb83d5",
+			id:       "1fbe0a408b347695be02ec74fcc97c9ca7cb83d5",
+			files:    "[{album/cd1/01.flac 10000} {album/cover.jpg 5000}]",
+			pieces:   2,
+			private:  true,
+			trackers: "[[udp://a.example.org:1337/announce udp://b.example.org:1337/announce] [https://c.example.org/announce]]",
+			webSeeds: "[https://w1.example.org/ https://w2.example.org/]",
+		},
+		{
+			file:     "hybrid",
+			v1:       "6085b98aa1126333cf1714b0d1a08f133fbc7c38",
+			v2:       "2cc838b9b1ad3012d42378d225358d898b1be06c3a38244b097e1d2471b8f3a0",
+			id:       "6085b98aa1126333cf1714b0d1a08f133fbc7c38",
+			files:    "[{hybrid/a.txt 20000} {hybrid/sub/b.txt 100}]",
+			pieces:   2,
+			trackers: "[[http://tracker.example.org/announce]]",
+			webSeeds: "[]",
+		},
+		{
+			file:     "v2",
+			v2:       "acdecbcaccf9d9bae3e5db6d5a8a9eb1af2c3771eb86ad46470e8d02c92ba42c",
+			id:       "acdecbcaccf9d9bae3e5db6d5a8a9eb1af2c3771",
+			files:    "[{v2only/x.bin 30000} {v2only/y.bin 0}]",
+			pieces:   2,
+			trackers: "[[http://tracker.example.org/announce]]",
+			webSeeds: "[]",
+		},
+	} {
+		mi, err := Load(filepath.Join("testdata", tt.file+".torrent"))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		got := []string{mi.InfoHashV1(), mi.InfoHashV2(), mi.ID(), fmt.Sprint(mi.Files()),
+			fmt.Sprint(mi.NumPieces()), fmt.Sprint(mi.Info.Private), fmt.Sprint(mi.Trackers()), fmt.Sprint(mi.WebSeeds())}
+		want := []string{tt.v1, tt.v2, tt.id, tt.files,
+			fmt.Sprint(tt.pieces), fmt.Sprint(tt.private), tt.trackers, tt.webSeeds}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: got %q, want %q", tt.file, got, want)
+				break
+			}
+		}
+		if mi.IsHybrid() != (tt.v1 != "" && tt.v2 != "") {
+			t.Errorf("%s: IsHybrid = %v", tt.file, mi.IsHybrid())
+		}
+	}
+
+	mi, err := Load(filepath.Join("testdata", "single.torrent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mi.CreatedBy != "qBittorrent v4.6.2" || mi.CreationTime().Unix() != 1700000000 || mi.TotalLength() != 40000 {
+		t.Errorf("creation info: %q %v %d", mi.CreatedBy, mi.CreationTime(), mi.TotalLength())
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, bad := range []string{
+		"d8:announce1:xe",
+		"d4:infod4:name1:x12:piece lengthi16384eee",
+		"d4:infod4:name1:x12:piece lengthi16384e6:pieces3:abcee",
+		"d4:infod12:piece lengthi16384e6:pieces20:aaaaaaaaaaaaaaaaaaaaee",
+		"d4:infod4:name1:x12:meta versioni2e12:piece lengthi16384eee",
+		"d4:infoi1ee",
+		"d4:info",
+	} {
+		if _, err := Parse([]byte(bad)); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) = %v, want ErrInvalid", bad, err)
+		}
+	}
+}
+
+func TestBuiltByHand(t *testing.T) {
+	for _, file := range []string{"single", "multi", "hybrid", "v2"} {
+		mi, err := Load(filepath.Join("testdata", file+".torrent"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hand := &MetaInfo{Announce: mi.Announce, Info: mi.Info}
+		if hand.InfoHashV1() != mi.InfoHashV1() || hand.InfoHashV2() != mi.InfoHashV2() {
+			t.Errorf("%s: info-hashes of Info = %s %s", file, hand.InfoHashV1(), hand.InfoHashV2())
+		}
+		b, err := hand.Bytes()
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		back, err := Parse(b)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if back.ID() != mi.ID() || back.Announce != mi.Announce || fmt.Sprint(back.Files()) != fmt.Sprint(mi.Files()) {
+			t.Errorf("%s: Bytes decodes to %s %q %v", file, back.ID(), back.Announce, back.Files())
+		}
+	}
+	if _, err := (&MetaInfo{}).Bytes(); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Bytes of an empty MetaInfo = %v", err)
+	}
+}
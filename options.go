@@ -0,0 +1,150 @@
+package qbt_apiv2
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*options)
+
+type options struct {
+	httpCli   *http.Client
+	transport http.RoundTripper
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	userAgent string
+	basePath  string
+	username  string
+	password  string
+}
+
+// WithHTTPClient makes the Client send requests through hc.
+// hc is copied, so later changes to it do not affect the Client.
+// A cookie jar is installed when hc has none.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) {
+		o.httpCli = hc
+	}
+}
+
+// WithTransport sets the RoundTripper used by the underlying http.Client.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = rt
+	}
+}
+
+// WithTimeout limits the duration of every single request,
+// including reading the response body.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to the WebUI.
+// It requires the transport to be an *http.Transport. cfg is copied,
+// so WithRootCAs and WithInsecureSkipVerify do not modify it.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg.Clone()
+	}
+}
+
+// WithRootCAs trusts the certificates in pool, e.g. a private CA
+// which signed the certificate of the WebUI.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(o *options) {
+		if o.tlsConfig == nil {
+			o.tlsConfig = new(tls.Config)
+		}
+		o.tlsConfig.RootCAs = pool
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the WebUI certificate.
+// Only use it for self-signed certificates in trusted networks.
+func WithInsecureSkipVerify() Option {
+	return func(o *options) {
+		if o.tlsConfig == nil {
+			o.tlsConfig = new(tls.Config)
+		}
+		o.tlsConfig.InsecureSkipVerify = true
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(ua string) Option {
+	return func(o *options) {
+		o.userAgent = ua
+	}
+}
+
+// WithBasePath mounts the API under a sub-path of the url,
+// e.g. WithBasePath("/qbittorrent") for a WebUI served by a reverse proxy at
+// https://example.com/qbittorrent/ makes requests go to
+// https://example.com/qbittorrent/api/v2/.
+func WithBasePath(p string) Option {
+	return func(o *options) {
+		o.basePath = p
+	}
+}
+
+// WithAuth sets the credentials used to login.
+// Without it the Client logs in with empty credentials,
+// which works when 'Bypass authentication for clients on localhost' is enabled.
+func WithAuth(username, password string) Option {
+	return func(o *options) {
+		o.username, o.password = username, password
+	}
+}
+
+func (o *options) apiURL(url string) string {
+	// ensure url ends with "/"
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	if bp := strings.Trim(o.basePath, "/"); bp != "" {
+		url += bp + "/"
+	}
+	return url + "api/v2/"
+}
+
+func (o *options) httpClient() (*http.Client, error) {
+	hc := new(http.Client)
+	if o.httpCli != nil {
+		*hc = *o.httpCli
+	}
+	if o.transport != nil {
+		hc.Transport = o.transport
+	}
+	if o.tlsConfig != nil {
+		var tr *http.Transport
+		switch t := hc.Transport.(type) {
+		case nil:
+			tr = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			tr = t.Clone()
+		default:
+			return nil, errors.New("tls config requires an *http.Transport")
+		}
+		tr.TLSClientConfig = o.tlsConfig
+		hc.Transport = tr
+	}
+	if o.timeout > 0 {
+		hc.Timeout = o.timeout
+	}
+	if hc.Jar == nil {
+		// create cookie jar
+		hc.Jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	}
+	return hc, nil
+}
@@ -0,0 +1,61 @@
+package qbt_apiv2
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewClientOptions(t *testing.T) {
+	srv := newFakeServerAt(t, "/qbt", true)
+	var ua string
+	srv.handle("app/version", func(w http.ResponseWriter, r *http.Request) {
+		ua = r.UserAgent()
+		w.Write([]byte("v4.6.2"))
+	})
+	cli := srv.client(t,
+		WithBasePath("/qbt/"),
+		WithInsecureSkipVerify(),
+		WithUserAgent("qbt-test/1.0"),
+		WithTimeout(5*time.Second),
+		WithAuth("admin", "adminadmin"),
+	)
+	if want := srv.URL + "/qbt/api/v2/"; cli.URL != want {
+		t.Errorf("URL = %q, want %q", cli.URL, want)
+	}
+	v, err := cli.GetVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "v4.6.2" || ua != "qbt-test/1.0" {
+		t.Errorf("version %q, user agent %q", v, ua)
+	}
+}
+
+func TestWithTLSConfigCopies(t *testing.T) {
+	srv := newFakeServerAt(t, "/", true)
+	srv.reply("app/version", "v4.6.2")
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	cli := srv.client(t, WithTLSConfig(cfg), WithInsecureSkipVerify())
+	if _, err := cli.GetVersion(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.InsecureSkipVerify {
+		t.Error("WithInsecureSkipVerify modified the config of the caller")
+	}
+}
+
+func TestNewClientTLSRequiresTransport(t *testing.T) {
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	_, err := NewClient("https://localhost", WithTransport(rt), WithInsecureSkipVerify())
+	if err == nil {
+		t.Fatal("expected error for tls config with a custom RoundTripper")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
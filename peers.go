@@ -0,0 +1,268 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Peer holds a peer object of `sync/torrentPeers`
+type Peer struct {
+	Client       string  `json:"client"`
+	Connection   string  `json:"connection"`
+	Country      string  `json:"country"`
+	CountryCode  string  `json:"country_code"`
+	DLSpeed      int     `json:"dl_speed"`
+	Downloaded   int     `json:"downloaded"`
+	Files        string  `json:"files"`
+	Flags        string  `json:"flags"`
+	FlagsDesc    string  `json:"flags_desc"`
+	IP           string  `json:"ip"`
+	PeerIDClient string  `json:"peer_id_client"`
+	Port         int     `json:"port"`
+	Progress     float64 `json:"progress"`
+	Relevance    float64 `json:"relevance"`
+	UpSpeed      int     `json:"up_speed"`
+	Uploaded     int     `json:"uploaded"`
+}
+
+// PeerSync holds the response of `sync/torrentPeers`,
+// Peers is keyed by "ip:port".
+type PeerSync struct {
+	FullUpdate   bool            `json:"full_update"`
+	Rid          int             `json:"rid"`
+	Peers        map[string]Peer `json:"peers"`
+	PeersRemoved []string        `json:"peers_removed"`
+	ShowFlags    bool            `json:"show_flags"`
+}
+
+type rawPeerSync struct {
+	Peers map[string]json.RawMessage `json:"peers"`
+}
+
+// GetTorrentPeers returns the peers of a torrent changed since rid,
+// rid 0 requests all of them. Incremental responses only contain the
+// fields which have changed, use a PeerTracker to merge them.
+// The server keeps a single `sync/torrentPeers` snapshot per session, whatever
+// the torrent, so a rid is only incremental for the last request of the session.
+func (c *Client) GetTorrentPeers(hash string, rid int) (PeerSync, error) {
+	return c.GetTorrentPeersContext(context.Background(), hash, rid)
+}
+
+// GetTorrentPeersContext is like GetTorrentPeers but uses ctx for the request.
+func (c *Client) GetTorrentPeersContext(ctx context.Context, hash string, rid int) (PeerSync, error) {
+	ps, _, err := c.getTorrentPeers(ctx, hash, rid)
+	return ps, err
+}
+
+func (c *Client) getTorrentPeers(ctx context.Context, hash string, rid int) (PeerSync, rawPeerSync, error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "sync/torrentPeers", Optional{
+		"hash": hash,
+		"rid":  rid,
+	})
+	err = RespOk(resp, err)
+	if err != nil {
+		return PeerSync{}, rawPeerSync{}, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return PeerSync{}, rawPeerSync{}, err
+	}
+	var (
+		ps  PeerSync
+		raw rawPeerSync
+	)
+	if err = json.Unmarshal(b, &ps); err != nil {
+		return PeerSync{}, rawPeerSync{}, err
+	}
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return PeerSync{}, rawPeerSync{}, err
+	}
+	return ps, raw, nil
+}
+
+// PeerTracker follows the peers of a single torrent through `sync/torrentPeers`.
+// It keeps its own `rid` and merges the partial updates field by field.
+// It is safe for concurrent use.
+//
+// The server keeps a single `sync/torrentPeers` snapshot per session, even for
+// different torrents. Polling several PeerTrackers, or GetTorrentPeers, with
+// the same Client makes them reset each other to full updates, which are still
+// merged correctly but resend every peer. Use a Client per PeerTracker to follow
+// several torrents efficiently.
+type PeerTracker struct {
+	c        *Client
+	hash     string
+	interval time.Duration
+
+	mu    sync.Mutex
+	rid   int
+	peers map[string]Peer
+}
+
+// NewPeerTracker creates a PeerTracker for the torrent hash, interval is the
+// time between two polls of Watch, 1.5s if it is not positive.
+func (c *Client) NewPeerTracker(hash string, interval time.Duration) *PeerTracker {
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	return &PeerTracker{
+		c:        c,
+		hash:     hash,
+		interval: interval,
+		peers:    make(map[string]Peer),
+	}
+}
+
+// Sync fetches the changes since the last call and merges them into the snapshot.
+func (p *PeerTracker) Sync() (PeerSync, error) {
+	return p.SyncContext(context.Background())
+}
+
+// SyncContext is like Sync but uses ctx for the request.
+func (p *PeerTracker) SyncContext(ctx context.Context) (PeerSync, error) {
+	ps, _, err := p.sync(ctx)
+	return ps, err
+}
+
+// sync returns the response and the events describing the changes.
+func (p *PeerTracker) sync(ctx context.Context) (PeerSync, []PeerEvent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ps, raw, err := p.c.getTorrentPeers(ctx, p.hash, p.rid)
+	if err != nil {
+		return PeerSync{}, nil, err
+	}
+	p.rid = ps.Rid
+	old := p.peers
+	var keys []string
+	if ps.FullUpdate {
+		p.peers = make(map[string]Peer, len(raw.Peers))
+		mergeJSON(p.peers, raw.Peers, nil)
+		keys = unionKeys(old, p.peers)
+	} else {
+		// only copy the peers touched by this update
+		old = make(map[string]Peer, len(raw.Peers)+len(ps.PeersRemoved))
+		touched := make(map[string]struct{}, len(raw.Peers)+len(ps.PeersRemoved))
+		for k := range raw.Peers {
+			touched[k] = struct{}{}
+		}
+		for _, k := range ps.PeersRemoved {
+			touched[k] = struct{}{}
+		}
+		for k := range touched {
+			if v, ok := p.peers[k]; ok {
+				old[k] = v
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range ps.PeersRemoved {
+			delete(p.peers, k)
+		}
+		mergeJSON(p.peers, raw.Peers, nil)
+	}
+	var events []PeerEvent
+	for _, k := range keys {
+		o, hadO := old[k]
+		n, hasN := p.peers[k]
+		switch {
+		case !hadO && hasN:
+			events = append(events, PeerEvent{Type: PeerAdded, Key: k, Peer: n})
+		case hadO && !hasN:
+			events = append(events, PeerEvent{Type: PeerRemoved, Key: k, Peer: o, Prev: o})
+		case hadO && hasN && o != n:
+			events = append(events, PeerEvent{Type: PeerChanged, Key: k, Peer: n, Prev: o})
+		}
+	}
+	return ps, events, nil
+}
+
+// Snapshot returns a copy of the peers known after the last Sync, keyed by "ip:port".
+func (p *PeerTracker) Snapshot() map[string]Peer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m := make(map[string]Peer, len(p.peers))
+	for k, v := range p.peers {
+		m[k] = v
+	}
+	return m
+}
+
+// PeerEventType identifies the change reported by a PeerEvent.
+type PeerEventType int
+
+const (
+	PeerError PeerEventType = iota
+	PeerAdded
+	PeerRemoved
+	PeerChanged
+)
+
+func (t PeerEventType) String() string {
+	switch t {
+	case PeerError:
+		return "Error"
+	case PeerAdded:
+		return "PeerAdded"
+	case PeerRemoved:
+		return "PeerRemoved"
+	case PeerChanged:
+		return "PeerChanged"
+	default:
+		return "Unknown"
+	}
+}
+
+// PeerEvent is a change observed by PeerTracker.Watch.
+type PeerEvent struct {
+	Type PeerEventType
+	// Key is "ip:port" of the peer
+	Key string
+	// Peer is the current state, or the last known state when removed.
+	Peer Peer
+	// Prev is the state before the change.
+	Prev Peer
+	// Err is set for PeerError, Watch keeps polling after it.
+	Err error
+}
+
+// Watch calls Sync every interval and emits the changes on the returned channel,
+// the first poll reports every peer as added. Failed polls are reported as
+// PeerError and retried with an exponential backoff.
+// The channel is closed when ctx is done.
+func (p *PeerTracker) Watch(ctx context.Context) <-chan PeerEvent {
+	ch := make(chan PeerEvent, 64)
+	go func() {
+		defer close(ch)
+		interval := p.interval
+		var backoff time.Duration
+		for {
+			_, events, err := p.sync(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				backoff = nextBackoff(backoff, interval)
+				if !sendEvent(ctx, ch, PeerEvent{Type: PeerError, Err: err}) || !sleep(ctx, backoff) {
+					return
+				}
+				continue
+			}
+			backoff = 0
+			for _, e := range events {
+				if !sendEvent(ctx, ch, e) {
+					return
+				}
+			}
+			if !sleep(ctx, interval) {
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,65 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPeerTracker(t *testing.T) {
+	payloads := []string{
+		`{"rid":1,"full_update":true,"show_flags":true,"peers":{
+			"1.1.1.1:6881":{"ip":"1.1.1.1","port":6881,"client":"qBittorrent 4.6.2","progress":0.1,"dl_speed":100},
+			"2.2.2.2:51413":{"ip":"2.2.2.2","port":51413,"client":"Transmission 4.0","progress":1}}}`,
+		`{"rid":2,"peers":{"1.1.1.1:6881":{"progress":0.2},"3.3.3.3:6881":{"ip":"3.3.3.3","port":6881}},
+			"peers_removed":["2.2.2.2:51413"]}`,
+		`{"rid":3}`,
+	}
+	n := 0
+	srv := newFakeServer(t)
+	srv.handle("sync/torrentPeers", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("hash") != "aaa" {
+			http.NotFound(w, r)
+			return
+		}
+		p := payloads[len(payloads)-1]
+		if n < len(payloads) {
+			p = payloads[n]
+			n++
+		}
+		w.Write([]byte(p))
+	})
+	cli := srv.client(t)
+
+	pt := cli.NewPeerTracker("aaa", 10*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := pt.Watch(ctx)
+
+	want := []struct {
+		typ PeerEventType
+		key string
+	}{
+		{PeerAdded, "1.1.1.1:6881"},
+		{PeerAdded, "2.2.2.2:51413"},
+		{PeerChanged, "1.1.1.1:6881"},
+		{PeerRemoved, "2.2.2.2:51413"},
+		{PeerAdded, "3.3.3.3:6881"},
+	}
+	for i, w := range want {
+		e := <-events
+		if e.Type != w.typ || e.Key != w.key {
+			t.Errorf("event %d = %v %s, want %v %s", i, e.Type, e.Key, w.typ, w.key)
+		}
+	}
+	cancel()
+	for range events {
+	}
+
+	peers := pt.Snapshot()
+	p := peers["1.1.1.1:6881"]
+	if len(peers) != 2 || p.Progress != 0.2 || p.Client != "qBittorrent 4.6.2" || p.DLSpeed != 100 {
+		t.Errorf("snapshot = %+v", peers)
+	}
+}
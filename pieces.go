@@ -0,0 +1,147 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// PieceState is the state of a piece returned by `torrents/pieceStates`
+type PieceState int
+
+const (
+	PieceNotDownloaded PieceState = iota
+	PieceDownloading
+	PieceDownloaded
+)
+
+func (s PieceState) String() string {
+	switch s {
+	case PieceNotDownloaded:
+		return "Not downloaded"
+	case PieceDownloading:
+		return "Downloading"
+	case PieceDownloaded:
+		return "Downloaded"
+	default:
+		return "Unknown"
+	}
+}
+
+// GetPieceStates returns the state of every piece of a torrent.
+func (c *Client) GetPieceStates(hash string) ([]PieceState, error) {
+	return c.GetPieceStatesContext(context.Background(), hash)
+}
+
+// GetPieceStatesContext is like GetPieceStates but uses ctx for the request.
+func (c *Client) GetPieceStatesContext(ctx context.Context, hash string) ([]PieceState, error) {
+	var states []PieceState
+	err := c.getJSON(ctx, "torrents/pieceStates", Optional{"hash": hash}, &states)
+	return states, err
+}
+
+// GetPieceHashes returns the hex encoded hash of every piece of a torrent.
+func (c *Client) GetPieceHashes(hash string) ([]string, error) {
+	return c.GetPieceHashesContext(context.Background(), hash)
+}
+
+// GetPieceHashesContext is like GetPieceHashes but uses ctx for the request.
+func (c *Client) GetPieceHashesContext(ctx context.Context, hash string) ([]string, error) {
+	var hashes []string
+	err := c.getJSON(ctx, "torrents/pieceHashes", Optional{"hash": hash}, &hashes)
+	return hashes, err
+}
+
+// getJSON performs a request and decodes its JSON response into v.
+func (c *Client) getJSON(ctx context.Context, endpoint string, opt Optional, v any) error {
+	resp, err := c.postXwwwFormUrlencoded(ctx, endpoint, opt)
+	err = RespOk(resp, err)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// PieceProgress counts the states of the pieces of a file.
+// Pieces at the boundaries may be shared with the neighbouring files.
+type PieceProgress struct {
+	Pieces      int
+	Downloaded  int
+	Downloading int
+}
+
+// Progress returns the fraction of downloaded pieces, 1 for a file without pieces.
+func (p PieceProgress) Progress() float64 {
+	if p.Pieces == 0 {
+		return 1
+	}
+	return float64(p.Downloaded) / float64(p.Pieces)
+}
+
+// CountPieces counts the states of the pieces in pieceRange, the inclusive
+// range of piece indexes `piece_range` of a file.
+func CountPieces(states []PieceState, pieceRange []int) PieceProgress {
+	var p PieceProgress
+	if len(pieceRange) != 2 {
+		return p
+	}
+	first, last := pieceRange[0], pieceRange[1]
+	if first < 0 {
+		first = 0
+	}
+	if last >= len(states) {
+		last = len(states) - 1
+	}
+	if first > last {
+		return p
+	}
+	for _, s := range states[first : last+1] {
+		p.Pieces++
+		switch s {
+		case PieceDownloaded:
+			p.Downloaded++
+		case PieceDownloading:
+			p.Downloading++
+		}
+	}
+	return p
+}
+
+// PieceProgress counts the states of the pieces of f.
+func (f TorrentFile) PieceProgress(states []PieceState) PieceProgress {
+	return CountPieces(states, f.PieceRange)
+}
+
+// PieceProgress counts the states of the pieces of f.
+func (f File) PieceProgress(states []PieceState) PieceProgress {
+	return CountPieces(states, f.PieceRange)
+}
+
+// GetFilesPieceProgress computes the completion of every file of a torrent
+// from its piece states, independently of the progress reported by the server.
+// The result is in the order of `torrents/files`.
+func (c *Client) GetFilesPieceProgress(hash string) ([]PieceProgress, error) {
+	return c.GetFilesPieceProgressContext(context.Background(), hash)
+}
+
+// GetFilesPieceProgressContext is like GetFilesPieceProgress but uses ctx for the requests.
+func (c *Client) GetFilesPieceProgressContext(ctx context.Context, hash string) ([]PieceProgress, error) {
+	files, err := c.GetTorrentContentsContext(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	states, err := c.GetPieceStatesContext(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	ps := make([]PieceProgress, len(files))
+	for i, f := range files {
+		ps[i] = f.PieceProgress(states)
+	}
+	return ps, nil
+}
@@ -0,0 +1,37 @@
+package qbt_apiv2
+
+import (
+	"testing"
+)
+
+func TestGetFilesPieceProgress(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("torrents/pieceStates", `[2,2,2,1,0,0,2,2]`)
+	srv.reply("torrents/files", `[
+		{"index":0,"name":"a.mkv","size":300,"progress":0.9,"piece_range":[0,2]},
+		{"index":1,"name":"b.mkv","size":300,"progress":0.1,"piece_range":[2,5]},
+		{"index":2,"name":"c.nfo","size":10,"progress":1,"piece_range":[6,7]},
+		{"index":3,"name":"empty","size":0,"progress":1,"piece_range":[8,7]}]`)
+	cli := srv.client(t)
+	ps, err := cli.GetFilesPieceProgress("aaa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PieceProgress{
+		{Pieces: 3, Downloaded: 3},
+		{Pieces: 4, Downloaded: 1, Downloading: 1},
+		{Pieces: 2, Downloaded: 2},
+		{},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("got %d files", len(ps))
+	}
+	for i := range want {
+		if ps[i] != want[i] {
+			t.Errorf("file %d: %+v, want %+v", i, ps[i], want[i])
+		}
+	}
+	if ps[1].Progress() != 0.25 || ps[3].Progress() != 1 {
+		t.Errorf("progress = %v, %v", ps[1].Progress(), ps[3].Progress())
+	}
+}
@@ -0,0 +1,156 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// IncreasePriority moves torrents one position up in the queue.
+// The queue methods fail with ErrQueueingDisabled if torrent queueing is disabled.
+func (c *Client) IncreasePriority(hashes ...string) error {
+	return c.IncreasePriorityContext(context.Background(), hashes...)
+}
+
+// IncreasePriorityContext is like IncreasePriority but uses ctx for the request.
+func (c *Client) IncreasePriorityContext(ctx context.Context, hashes ...string) error {
+	return c.queueAction(ctx, "torrents/increasePrio", hashes)
+}
+
+// DecreasePriority moves torrents one position down in the queue.
+func (c *Client) DecreasePriority(hashes ...string) error {
+	return c.DecreasePriorityContext(context.Background(), hashes...)
+}
+
+// DecreasePriorityContext is like DecreasePriority but uses ctx for the request.
+func (c *Client) DecreasePriorityContext(ctx context.Context, hashes ...string) error {
+	return c.queueAction(ctx, "torrents/decreasePrio", hashes)
+}
+
+// TopPriority moves torrents to the top of the queue.
+func (c *Client) TopPriority(hashes ...string) error {
+	return c.TopPriorityContext(context.Background(), hashes...)
+}
+
+// TopPriorityContext is like TopPriority but uses ctx for the request.
+func (c *Client) TopPriorityContext(ctx context.Context, hashes ...string) error {
+	return c.queueAction(ctx, "torrents/topPrio", hashes)
+}
+
+// BottomPriority moves torrents to the bottom of the queue.
+func (c *Client) BottomPriority(hashes ...string) error {
+	return c.BottomPriorityContext(context.Background(), hashes...)
+}
+
+// BottomPriorityContext is like BottomPriority but uses ctx for the request.
+func (c *Client) BottomPriorityContext(ctx context.Context, hashes ...string) error {
+	return c.queueAction(ctx, "torrents/bottomPrio", hashes)
+}
+
+func (c *Client) queueAction(ctx context.Context, endpoint string, hashes []string) error {
+	err := c.postAction(ctx, endpoint, Optional{
+		"hashes": strings.Join(hashes, "|"),
+	})
+	return withStatusErr(err, http.StatusConflict, ErrQueueingDisabled)
+}
+
+// ReorderQueue moves the queued torrents hashes to the top of the queue in the
+// given order, the other torrents keep their relative order after them.
+// It moves as few torrents as possible, one `torrents/topPrio` call each.
+// It fails with ErrQueueingDisabled if torrent queueing is disabled,
+// and without moving anything if a torrent is not in the queue.
+func (c *Client) ReorderQueue(hashes ...string) error {
+	return c.ReorderQueueContext(context.Background(), hashes...)
+}
+
+// ReorderQueueContext is like ReorderQueue but uses ctx for the requests.
+func (c *Client) ReorderQueueContext(ctx context.Context, hashes ...string) error {
+	cfg, err := c.GetPreferencesContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !cfg.QueueingEnabled {
+		return ErrQueueingDisabled
+	}
+	ts, err := c.TorrentListContext(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// the priority is the queue position starting at 1, it is not positive for
+	// the torrents outside of the queue, e.g. the seeding ones
+	var queued []Torrent
+	for _, t := range ts {
+		if t.Priority > 0 {
+			queued = append(queued, t)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool {
+		return queued[i].Priority < queued[j].Priority
+	})
+	current := make([]string, len(queued))
+	for i, t := range queued {
+		current[i] = t.Hash
+	}
+	seen := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		if seen[h] {
+			return fmt.Errorf("torrent %s is listed twice", h)
+		}
+		seen[h] = true
+		if !containsStr(current, h) {
+			return fmt.Errorf("torrent %s is not queued", h)
+		}
+	}
+	for _, h := range queueMoves(current, hashes) {
+		err = c.TopPriorityContext(ctx, h)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueMoves returns the torrents to move to the top of the queue current, in
+// order, so that it starts with desired. Moving desired[j-1] to desired[0] to
+// the top suffices if the rest of the queue already starts with desired[j:],
+// the smallest such j is chosen.
+func queueMoves(current, desired []string) []string {
+	for j := 0; j < len(desired); j++ {
+		if startsWith(without(current, desired[:j]), desired[j:]) {
+			return reversed(desired[:j])
+		}
+	}
+	return reversed(desired)
+}
+
+func without(s, remove []string) []string {
+	r := make([]string, 0, len(s))
+	for _, v := range s {
+		if !containsStr(remove, v) {
+			r = append(r, v)
+		}
+	}
+	return r
+}
+
+func startsWith(s, prefix []string) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func reversed(s []string) []string {
+	r := make([]string, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
@@ -0,0 +1,60 @@
+package qbt_apiv2
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestQueueMoves(t *testing.T) {
+	for _, tt := range []struct {
+		current, desired, want []string
+	}{
+		{[]string{"a", "b", "c"}, []string{"a", "b"}, []string{}},
+		{[]string{"a", "b", "c"}, []string{"c", "a"}, []string{"c"}},
+		{[]string{"a", "b", "c", "d"}, []string{"b", "d", "a"}, []string{"d", "b"}},
+		{[]string{"a", "b", "c", "d"}, []string{"d", "c", "b", "a"}, []string{"b", "c", "d"}},
+		{[]string{"a", "b", "c"}, []string{"c", "b", "a"}, []string{"b", "c"}},
+	} {
+		got := queueMoves(tt.current, tt.desired)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("queueMoves(%v, %v) = %v, want %v", tt.current, tt.desired, got, tt.want)
+		}
+	}
+}
+
+func TestReorderQueue(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("app/version", "v4.6.2")
+	srv.reply("app/preferences", `{"queueing_enabled":true}`)
+	srv.reply("torrents/info", `[
+		{"hash":"c","priority":3},{"hash":"seed","priority":0},
+		{"hash":"a","priority":1},{"hash":"b","priority":2}]`)
+	var moved []string
+	srv.handle("torrents/topPrio", func(w http.ResponseWriter, r *http.Request) {
+		moved = append(moved, r.FormValue("hashes"))
+	})
+	cli := srv.client(t)
+	if err := cli.ReorderQueue("c", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(moved) != "[c]" {
+		t.Errorf("topPrio calls = %v", moved)
+	}
+	if err := cli.ReorderQueue("seed"); err == nil {
+		t.Error("reordered a torrent outside of the queue")
+	}
+
+	srv.reply("app/preferences", `{"queueing_enabled":false}`)
+	if err := cli.ReorderQueue("a"); !errors.Is(err, ErrQueueingDisabled) {
+		t.Errorf("ReorderQueue = %v, want ErrQueueingDisabled", err)
+	}
+	srv.handle("torrents/bottomPrio", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusConflict)
+	})
+	err := cli.BottomPriority("a")
+	if !errors.Is(err, ErrQueueingDisabled) || !errors.Is(err, ErrConflict) {
+		t.Errorf("BottomPriority = %v, want ErrQueueingDisabled", err)
+	}
+}
@@ -4,8 +4,8 @@
 package qbt_apiv2
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 )
 
@@ -65,7 +65,12 @@ type AutoDLRule struct {
 
 // RSS All RSS API methods are under "rss", e.g.: /api/v2/rss/methodName.
 func (c *Client) AddFolder(path string) error {
-	resp, err := c.postXwwwFormUrlencoded("rss/addFolder", Optional{
+	return c.AddFolderContext(context.Background(), path)
+}
+
+// AddFolderContext is like AddFolder but uses ctx for the request.
+func (c *Client) AddFolderContext(ctx context.Context, path string) error {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/addFolder", Optional{
 		"path": path,
 	})
 	err = RespOk(resp, err)
@@ -77,21 +82,18 @@ func (c *Client) AddFolder(path string) error {
 }
 
 func (c *Client) AddFeed(url, path string) error {
+	return c.AddFeedContext(context.Background(), url, path)
+}
+
+// AddFeedContext is like AddFeed but uses ctx for the request.
+func (c *Client) AddFeedContext(ctx context.Context, url, path string) error {
 	opt := Optional{
 		"url":  url,
 		"path": path,
 	}
-	resp, err := c.postXwwwFormUrlencoded("rss/addFeed", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/addFeed", opt)
 	err = RespOk(resp, err)
 	if err != nil {
-		if resp.StatusCode == 409 {
-			defer resp.Body.Close()
-			b, e := io.ReadAll(resp.Body)
-			if e != nil {
-				return err
-			}
-			return fmt.Errorf(string(b)+": %w", err)
-		}
 		return err
 	}
 	ignrBody(resp.Body)
@@ -99,26 +101,29 @@ func (c *Client) AddFeed(url, path string) error {
 }
 
 func (c *Client) RemoveItem(path string) error {
-	resp, err := c.postXwwwFormUrlencoded("rss/removeItem", Optional{
+	return c.RemoveItemContext(context.Background(), path)
+}
+
+// RemoveItemContext is like RemoveItem but uses ctx for the request.
+func (c *Client) RemoveItemContext(ctx context.Context, path string) error {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/removeItem", Optional{
 		"path": path,
 	})
 	err = RespOk(resp, err)
 	if err != nil {
-		if resp.StatusCode == 409 {
-			defer resp.Body.Close()
-			b, e := io.ReadAll(resp.Body)
-			if e != nil {
-				return err
-			}
-			return fmt.Errorf(string(b)+": %w", err)
-		}
 		return err
 	}
+	ignrBody(resp.Body)
 	return nil
 }
 
 func (c *Client) MoveItem(dst, src string) error {
-	resp, err := c.postXwwwFormUrlencoded("rss/moveItem", Optional{
+	return c.MoveItemContext(context.Background(), dst, src)
+}
+
+// MoveItemContext is like MoveItem but uses ctx for the request.
+func (c *Client) MoveItemContext(ctx context.Context, dst, src string) error {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/moveItem", Optional{
 		"itemPath": src,
 		"destPath": dst,
 	})
@@ -131,11 +136,16 @@ func (c *Client) MoveItem(dst, src string) error {
 }
 
 func (c *Client) GetAllItems(withData bool) (RssItem, error) {
+	return c.GetAllItemsContext(context.Background(), withData)
+}
+
+// GetAllItemsContext is like GetAllItems but uses ctx for the request.
+func (c *Client) GetAllItemsContext(ctx context.Context, withData bool) (RssItem, error) {
 	opt := Optional{}
 	if withData {
 		opt["withData"] = true
 	}
-	resp, err := c.postXwwwFormUrlencoded("rss/items", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/items", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return nil, err
@@ -151,13 +161,18 @@ func (c *Client) GetAllItems(withData bool) (RssItem, error) {
 }
 
 func (c *Client) MarkAsRead(itemPath, articleId string) error {
+	return c.MarkAsReadContext(context.Background(), itemPath, articleId)
+}
+
+// MarkAsReadContext is like MarkAsRead but uses ctx for the request.
+func (c *Client) MarkAsReadContext(ctx context.Context, itemPath, articleId string) error {
 	opt := Optional{
 		"itemPath": itemPath,
 	}
 	if articleId != "" {
 		opt["articleId"] = articleId
 	}
-	resp, err := c.postXwwwFormUrlencoded("rss/markAsRead", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/markAsRead", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -167,10 +182,15 @@ func (c *Client) MarkAsRead(itemPath, articleId string) error {
 }
 
 func (c *Client) RefreshItem(itemPath string) error {
+	return c.RefreshItemContext(context.Background(), itemPath)
+}
+
+// RefreshItemContext is like RefreshItem but uses ctx for the request.
+func (c *Client) RefreshItemContext(ctx context.Context, itemPath string) error {
 	opt := Optional{
 		"itemPath": itemPath,
 	}
-	resp, err := c.postXwwwFormUrlencoded("rss/refreshItem", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/refreshItem", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -181,12 +201,17 @@ func (c *Client) RefreshItem(itemPath string) error {
 
 // Set auto-downloading rule
 func (c *Client) SetAutoDLRule(ruleName string, ruleDef AutoDLRule) error {
+	return c.SetAutoDLRuleContext(context.Background(), ruleName, ruleDef)
+}
+
+// SetAutoDLRuleContext is like SetAutoDLRule but uses ctx for the request.
+func (c *Client) SetAutoDLRuleContext(ctx context.Context, ruleName string, ruleDef AutoDLRule) error {
 	b, _ := json.Marshal(ruleDef)
 	opt := Optional{
 		"ruleName": ruleName,
 		"ruleDef":  string(b),
 	}
-	resp, err := c.postXwwwFormUrlencoded("rss/setRule", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/setRule", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -197,7 +222,12 @@ func (c *Client) SetAutoDLRule(ruleName string, ruleDef AutoDLRule) error {
 
 // RnAutoDLRule Rename auto-downloading rule
 func (c *Client) RnAutoDLRule(newName, oldName string) error {
-	resp, err := c.postXwwwFormUrlencoded("rss/renameRule", Optional{
+	return c.RnAutoDLRuleContext(context.Background(), newName, oldName)
+}
+
+// RnAutoDLRuleContext is like RnAutoDLRule but uses ctx for the request.
+func (c *Client) RnAutoDLRuleContext(ctx context.Context, newName, oldName string) error {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/renameRule", Optional{
 		"ruleName":    oldName,
 		"newRuleName": newName,
 	})
@@ -211,11 +241,16 @@ func (c *Client) RnAutoDLRule(newName, oldName string) error {
 
 // RmAutoDLRule Remove auto-downloading rule
 func (c *Client) RmAutoDLRule(ruleName string) error {
+	return c.RmAutoDLRuleContext(context.Background(), ruleName)
+}
+
+// RmAutoDLRuleContext is like RmAutoDLRule but uses ctx for the request.
+func (c *Client) RmAutoDLRuleContext(ctx context.Context, ruleName string) error {
 
 	opt := Optional{
 		"ruleName": ruleName,
 	}
-	resp, err := c.postXwwwFormUrlencoded("rss/removeRule", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/removeRule", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -226,7 +261,12 @@ func (c *Client) RmAutoDLRule(ruleName string) error {
 
 // LsAutoDLRule Get all auto-downloading rules
 func (c *Client) LsAutoDLRule() (map[string]AutoDLRule, error) {
-	resp, err := c.postXwwwFormUrlencoded("rss/rules", nil)
+	return c.LsAutoDLRuleContext(context.Background())
+}
+
+// LsAutoDLRuleContext is like LsAutoDLRule but uses ctx for the request.
+func (c *Client) LsAutoDLRuleContext(ctx context.Context) (map[string]AutoDLRule, error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/rules", nil)
 	err = RespOk(resp, err)
 	if err != nil {
 		return nil, err
@@ -242,7 +282,12 @@ func (c *Client) LsAutoDLRule() (map[string]AutoDLRule, error) {
 }
 
 func (c *Client) LsArtMatchRule(ruleName string) (map[string][]string, error) {
-	resp, err := c.postXwwwFormUrlencoded("rss/matchingArticles", Optional{
+	return c.LsArtMatchRuleContext(context.Background(), ruleName)
+}
+
+// LsArtMatchRuleContext is like LsArtMatchRule but uses ctx for the request.
+func (c *Client) LsArtMatchRuleContext(ctx context.Context, ruleName string) (map[string][]string, error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "rss/matchingArticles", Optional{
 		"ruleName": ruleName,
 	})
 	err = RespOk(resp, err)
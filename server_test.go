@@ -0,0 +1,82 @@
+package qbt_apiv2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServer is a minimal qBittorrent WebUI used by the tests
+// which do not need a running qBittorrent instance.
+type fakeServer struct {
+	*httptest.Server
+	prefix string
+
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	calls    map[string]int
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	return newFakeServerAt(t, "/", false)
+}
+
+func newFakeServerAt(t *testing.T, prefix string, tls bool) *fakeServer {
+	s := &fakeServer{
+		prefix:   strings.TrimSuffix(prefix, "/") + "/api/v2/",
+		handlers: make(map[string]http.HandlerFunc),
+		calls:    make(map[string]int),
+	}
+	s.handle("auth/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: "sid", Path: "/"})
+		w.Write([]byte(ResponseBodyOK))
+	})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := strings.TrimPrefix(r.URL.Path, s.prefix)
+		s.mu.Lock()
+		s.calls[endpoint]++
+		hf, ok := s.handlers[endpoint]
+		s.mu.Unlock()
+		if !ok || !strings.HasPrefix(r.URL.Path, s.prefix) {
+			http.NotFound(w, r)
+			return
+		}
+		hf(w, r)
+	})
+	if tls {
+		s.Server = httptest.NewTLSServer(h)
+	} else {
+		s.Server = httptest.NewServer(h)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) handle(endpoint string, h http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[endpoint] = h
+}
+
+// reply registers a handler which always responds body with status 200.
+func (s *fakeServer) reply(endpoint, body string) {
+	s.handle(endpoint, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+}
+
+func (s *fakeServer) called(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[endpoint]
+}
+
+func (s *fakeServer) client(t *testing.T, opts ...Option) *Client {
+	cli, err := NewClient(s.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
@@ -4,6 +4,7 @@
 package qbt_apiv2
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 )
@@ -39,6 +40,8 @@ type ServerState struct {
 type Categories struct {
 	Name     string `json:"name"`
 	SavePath string `json:"savePath"`
+	// DownloadPath is the path of the incomplete torrents, qBittorrent v4.4.0+.
+	DownloadPath DownloadPath `json:"download_path"`
 }
 
 // Sync holds the sync response struct which contains
@@ -65,56 +68,136 @@ type MainData struct {
 	Trackers    map[string][]string
 }
 
-func (c *Client) getMainData(rid int) (Sync, error) {
-	resp, err := c.postXwwwFormUrlencoded("sync/maindata", Optional{
+// rawSync holds the objects of a `sync/maindata` response which only contain
+// the changed fields in incremental updates, they are merged field by field.
+type rawSync struct {
+	ServerState json.RawMessage            `json:"server_state"`
+	Torrents    map[string]json.RawMessage `json:"torrents"`
+	Categories  map[string]json.RawMessage `json:"categories"`
+}
+
+func decodeSync(b []byte) (Sync, rawSync, error) {
+	var (
+		s   Sync
+		raw rawSync
+	)
+	if err := json.Unmarshal(b, &s); err != nil {
+		return Sync{}, rawSync{}, err
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return Sync{}, rawSync{}, err
+	}
+	// the hash is only sent as the key of the map
+	for k, t := range s.Torrents {
+		t.Hash = k
+		s.Torrents[k] = t
+	}
+	return s, raw, nil
+}
+
+func (c *Client) getMainData(ctx context.Context, rid int) (Sync, rawSync, error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "sync/maindata", Optional{
 		"rid": rid,
 	})
 	err = RespOk(resp, err)
 	if err != nil {
-		return Sync{}, err
+		return Sync{}, rawSync{}, err
 	}
 	defer resp.Body.Close()
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return Sync{}, err
-	}
-	s := new(Sync)
-	err = json.Unmarshal(b, s)
-	if err != nil {
-		return Sync{}, err
+		return Sync{}, rawSync{}, err
 	}
-	return *s, nil
+	return decodeSync(b)
 }
 
 func (c *Client) GetMainData() (Sync, error) {
-	s, err := c.getMainData(c.rid)
+	return c.GetMainDataContext(context.Background())
+}
+
+// GetMainDataContext is like GetMainData but uses ctx for the request.
+func (c *Client) GetMainDataContext(ctx context.Context) (Sync, error) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	return c.syncMainData(ctx)
+}
+
+// syncMainData must be called with syncMu held.
+func (c *Client) syncMainData(ctx context.Context) (Sync, error) {
+	s, raw, err := c.getMainData(ctx, c.rid)
 	if err != nil {
 		return Sync{}, err
 	}
 	c.rid = s.Rid
-	if s.FullUpdate {
-		c.mainData = new(MainData)
-		c.mainData.ServerState,
-			c.mainData.Torrents,
-			c.mainData.Categories,
-			c.mainData.Trackers,
-			c.mainData.Tags = s.ServerState, s.Torrents, s.Categories, s.Trackers, s.Tags
-	} else {
-		updateMainData(c.mainData, s)
+	if s.FullUpdate || c.mainData == nil {
+		c.mainData = newMainData()
 	}
+	updateMainData(c.mainData, s, raw)
 	return s, err
 }
+
 func (c *Client) GetMainDataFull() (MainData, error) {
-	_, err := c.GetMainData()
+	return c.GetMainDataFullContext(context.Background())
+}
+
+// GetMainDataFullContext is like GetMainDataFull but uses ctx for the request.
+// The returned MainData is a deep copy of the cached state.
+func (c *Client) GetMainDataFullContext(ctx context.Context) (MainData, error) {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	_, err := c.syncMainData(ctx)
 	if err != nil {
 		return MainData{}, err
 	}
-	return *c.mainData, nil
+	return c.mainData.Clone(), nil
+}
+
+// Clone returns a deep copy of m.
+func (m MainData) Clone() MainData {
+	n := MainData{
+		ServerState: m.ServerState.clone(),
+		Torrents:    make(map[string]Torrent, len(m.Torrents)),
+		Categories:  make(map[string]Categories, len(m.Categories)),
+		Tags:        append([]string(nil), m.Tags...),
+		Trackers:    make(map[string][]string, len(m.Trackers)),
+	}
+	for k, v := range m.Torrents {
+		n.Torrents[k] = v
+	}
+	for k, v := range m.Categories {
+		n.Categories[k] = v
+	}
+	for k, v := range m.Trackers {
+		n.Trackers[k] = append([]string(nil), v...)
+	}
+	return n
 }
 
-func updateMainData(m *MainData, s Sync) {
-	raw, _ := json.Marshal(s.ServerState)
-	_ = json.Unmarshal(raw, &m.ServerState)
+func (s ServerState) clone() ServerState {
+	for _, b := range []**bool{&s.Queueing, &s.UseAltSpeedLimits, &s.UseSubcategories} {
+		if *b != nil {
+			v := **b
+			*b = &v
+		}
+	}
+	return s
+}
+
+func newMainData() *MainData {
+	return &MainData{
+		Torrents:   make(map[string]Torrent),
+		Categories: make(map[string]Categories),
+		Trackers:   make(map[string][]string),
+	}
+}
+
+// updateMainData applies a `sync/maindata` response to m.
+// Torrents, categories and the server state are merged field by field from raw,
+// as incremental responses only contain the fields which have changed.
+func updateMainData(m *MainData, s Sync, raw rawSync) {
+	if len(raw.ServerState) > 0 {
+		_ = json.Unmarshal(raw.ServerState, &m.ServerState)
+	}
 	for _, k := range s.TorrentsRemoved {
 		delete(m.Torrents, k)
 	}
@@ -124,30 +207,54 @@ func updateMainData(m *MainData, s Sync) {
 	for _, k := range s.TrackersRemoved {
 		delete(m.Trackers, k)
 	}
-	it := make(map[string]struct{})
-	if len(s.TrackersRemoved) > 0 {
+	if len(s.TagsRemoved) > 0 {
+		rm := make(map[string]struct{}, len(s.TagsRemoved))
 		for _, k := range s.TagsRemoved {
-			it[k] = struct{}{}
+			rm[k] = struct{}{}
 		}
 		var nTag []string
 		for _, t := range m.Tags {
-			if _, e := it[t]; !e {
+			if _, e := rm[t]; !e {
 				nTag = append(nTag, t)
 			}
 		}
 		m.Tags = nTag
 	}
-	for k, v := range s.Torrents {
-		m.Torrents[k] = v
+	mergeJSON(m.Torrents, raw.Torrents, func(k string, t *Torrent) {
+		t.Hash = k
+	})
+	mergeJSON(m.Categories, raw.Categories, nil)
+	for k, v := range s.Trackers {
+		m.Trackers[k] = append([]string(nil), v...)
 	}
-
-	for k, v := range s.Categories {
-		m.Categories[k] = v
+	for _, t := range s.Tags {
+		if !containsStr(m.Tags, t) {
+			m.Tags = append(m.Tags, t)
+		}
 	}
+}
 
-	for k, v := range s.Trackers {
-		m.Trackers[k] = v
+// mergeJSON decodes every object of raw onto the value of the same key in m,
+// so the fields missing in the object keep their values. fix, if not nil,
+// is called for every merged value.
+func mergeJSON[V any](m map[string]V, raw map[string]json.RawMessage, fix func(k string, v *V)) {
+	for k, b := range raw {
+		v := m[k]
+		if err := json.Unmarshal(b, &v); err != nil {
+			continue
+		}
+		if fix != nil {
+			fix(k, &v)
+		}
+		m[k] = v
 	}
-	m.Tags = append(m.Tags, s.Tags...)
+}
 
+func containsStr(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
 }
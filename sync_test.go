@@ -0,0 +1,188 @@
+package qbt_apiv2
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// mainDataHandler answers `sync/maindata` with a full update for rid 0
+// and an incremental update changing the progress of one torrent otherwise.
+func mainDataHandler(w http.ResponseWriter, r *http.Request) {
+	rid, _ := strconv.Atoi(r.FormValue("rid"))
+	if rid == 0 {
+		fmt.Fprint(w, `{"rid":1,"full_update":true,
+			"server_state":{"connection_status":"connected","dl_info_speed":10},
+			"torrents":{"aaa":{"name":"a","progress":0},"bbb":{"name":"b","progress":1}},
+			"categories":{"tv":{"name":"tv","savePath":"/tv"}},
+			"tags":["x"],
+			"trackers":{"http://t/announce":["aaa","bbb"]}}`)
+		return
+	}
+	fmt.Fprintf(w, `{"rid":%d,"torrents":{"aaa":{"progress":0.%d}}}`, rid+1, rid%10)
+}
+
+func TestConcurrentClient(t *testing.T) {
+	srv := newFakeServer(t)
+	ss := new(sessions)
+	srv.handle("auth/login", ss.login)
+	srv.handle("sync/maindata", ss.guard(mainDataHandler))
+	srv.handle("torrents/info", ss.guard(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"hash":"aaa","name":"a"}]`)
+	}))
+	cli := srv.client(t, WithAuth("admin", "adminadmin"))
+
+	// every round starts with an expired session,
+	// so the goroutines race to re-authenticate
+	for round := 0; round < 3; round++ {
+		ss.expire()
+		var wg sync.WaitGroup
+		for i := 0; i < 9; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					switch i % 3 {
+					case 0:
+						if _, err := cli.GetMainData(); err != nil {
+							t.Error(err)
+						}
+					case 1:
+						m, err := cli.GetMainDataFull()
+						if err != nil {
+							t.Error(err)
+							continue
+						}
+						// the snapshot must not share state with the client
+						m.Torrents["zzz"] = Torrent{}
+						m.Trackers["http://t/announce"][0] = ""
+						m.Tags = append(m.Tags[:0], "y")
+					case 2:
+						if _, err := cli.TorrentList(nil); err != nil {
+							t.Error(err)
+						}
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+	}
+	if n := srv.called("auth/login"); n != 4 {
+		t.Errorf("login called %d times, want 4", n)
+	}
+
+	m, err := cli.GetMainDataFull()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Torrents["zzz"]; ok || m.Trackers["http://t/announce"][0] != "aaa" || m.Tags[0] != "x" {
+		t.Errorf("snapshot modifications leaked into the client: %+v", m)
+	}
+}
+
+func TestUpdateMainData(t *testing.T) {
+	// recorded from qBittorrent v4.6.2, shortened
+	full := `{"rid":1,"full_update":true,
+		"server_state":{"connection_status":"connected","dl_info_speed":2048,"up_info_speed":512,"queueing":true},
+		"torrents":{
+			"aaa":{"name":"ubuntu.iso","save_path":"/dl","size":100,"progress":0.5,"state":"downloading","tags":"linux"},
+			"bbb":{"name":"debian.iso","save_path":"/dl","size":200,"progress":1,"state":"uploading","tags":""}},
+		"categories":{"os":{"name":"os","savePath":"/dl/os"}},
+		"tags":["linux","iso"],
+		"trackers":{"http://t1/announce":["aaa"],"http://t2/announce":["bbb"]}}`
+
+	tests := []struct {
+		name    string
+		payload string
+		check   func(t *testing.T, m *MainData)
+	}{
+		{
+			name:    "partial torrent keeps other fields",
+			payload: `{"rid":2,"torrents":{"aaa":{"progress":0.75}}}`,
+			check: func(t *testing.T, m *MainData) {
+				a := m.Torrents["aaa"]
+				if a.Progress != 0.75 || a.Name != "ubuntu.iso" || a.SavePath != "/dl" || a.Size != 100 || a.Hash != "aaa" {
+					t.Errorf("torrent not merged: %+v", a)
+				}
+			},
+		},
+		{
+			name:    "server state zero value",
+			payload: `{"rid":3,"server_state":{"dl_info_speed":0}}`,
+			check: func(t *testing.T, m *MainData) {
+				ss := m.ServerState
+				if ss.DLInfoSpeed != 0 || ss.UpInfoSpeed != 512 || ss.ConnectionStatus != "connected" || ss.Queueing == nil || !*ss.Queueing {
+					t.Errorf("server state not merged: %+v", ss)
+				}
+			},
+		},
+		{
+			name:    "tags removed and added without duplicates",
+			payload: `{"rid":4,"tags":["iso","new"],"tags_removed":["linux"]}`,
+			check: func(t *testing.T, m *MainData) {
+				if fmt.Sprint(m.Tags) != "[iso new]" {
+					t.Errorf("tags = %v", m.Tags)
+				}
+			},
+		},
+		{
+			name:    "category changed and added",
+			payload: `{"rid":5,"categories":{"os":{"savePath":"/os"},"tv":{"name":"tv","savePath":"/tv"}}}`,
+			check: func(t *testing.T, m *MainData) {
+				if c := m.Categories["os"]; c.Name != "os" || c.SavePath != "/os" {
+					t.Errorf("category not merged: %+v", c)
+				}
+				if len(m.Categories) != 2 {
+					t.Errorf("categories = %v", m.Categories)
+				}
+			},
+		},
+		{
+			name:    "removals",
+			payload: `{"rid":6,"torrents_removed":["bbb"],"categories_removed":["tv"],"trackers_removed":["http://t2/announce"]}`,
+			check: func(t *testing.T, m *MainData) {
+				if _, ok := m.Torrents["bbb"]; ok || len(m.Torrents) != 1 {
+					t.Errorf("torrents = %v", m.Torrents)
+				}
+				if _, ok := m.Categories["tv"]; ok {
+					t.Errorf("categories = %v", m.Categories)
+				}
+				if _, ok := m.Trackers["http://t2/announce"]; ok || len(m.Trackers) != 1 {
+					t.Errorf("trackers = %v", m.Trackers)
+				}
+			},
+		},
+		{
+			name:    "new torrent and tracker set",
+			payload: `{"rid":7,"torrents":{"ccc":{"name":"arch.iso","state":"metaDL"}},"trackers":{"http://t1/announce":["aaa","ccc"]}}`,
+			check: func(t *testing.T, m *MainData) {
+				if c := m.Torrents["ccc"]; c.Name != "arch.iso" || c.Hash != "ccc" {
+					t.Errorf("new torrent = %+v", c)
+				}
+				if fmt.Sprint(m.Trackers["http://t1/announce"]) != "[aaa ccc]" {
+					t.Errorf("trackers = %v", m.Trackers)
+				}
+			},
+		},
+	}
+
+	s, raw, err := decodeSync([]byte(full))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMainData()
+	updateMainData(m, s, raw)
+	// the payloads are applied in order, every case starts from the state left by the previous one
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, raw, err := decodeSync([]byte(tt.payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			updateMainData(m, s, raw)
+			tt.check(t, m)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagList holds the tags of a torrent, the server sends them joined by ", ".
+type TagList []string
+
+func (l *TagList) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*l = splitTags(s)
+	return nil
+}
+
+func (l TagList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(l, ", "))
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// GetTags returns every tag.
+func (c *Client) GetTags() ([]string, error) {
+	return c.GetTagsContext(context.Background())
+}
+
+// GetTagsContext is like GetTags but uses ctx for the request.
+func (c *Client) GetTagsContext(ctx context.Context) ([]string, error) {
+	var tags []string
+	err := c.getJSON(ctx, "torrents/tags", nil, &tags)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// CreateTags creates tags, the existing ones are ignored.
+func (c *Client) CreateTags(tags ...string) error {
+	return c.CreateTagsContext(context.Background(), tags...)
+}
+
+// CreateTagsContext is like CreateTags but uses ctx for the request.
+func (c *Client) CreateTagsContext(ctx context.Context, tags ...string) error {
+	return c.postAction(ctx, "torrents/createTags", Optional{
+		"tags": strings.Join(tags, ","),
+	})
+}
+
+// AddTags adds tags to torrents, creating the missing tags.
+func (c *Client) AddTags(tags []string, hashes ...string) error {
+	return c.AddTagsContext(context.Background(), tags, hashes...)
+}
+
+// AddTagsContext is like AddTags but uses ctx for the request.
+func (c *Client) AddTagsContext(ctx context.Context, tags []string, hashes ...string) error {
+	return c.postAction(ctx, "torrents/addTags", Optional{
+		"hashes": strings.Join(hashes, "|"),
+		"tags":   strings.Join(tags, ","),
+	})
+}
+
+// RemoveTags removes tags from torrents, no tags removes every tag.
+func (c *Client) RemoveTags(tags []string, hashes ...string) error {
+	return c.RemoveTagsContext(context.Background(), tags, hashes...)
+}
+
+// RemoveTagsContext is like RemoveTags but uses ctx for the request.
+func (c *Client) RemoveTagsContext(ctx context.Context, tags []string, hashes ...string) error {
+	return c.postAction(ctx, "torrents/removeTags", Optional{
+		"hashes": strings.Join(hashes, "|"),
+		"tags":   strings.Join(tags, ","),
+	})
+}
+
+// SetTorrentTags replaces the tags of torrents with tags. It adds and
+// removes only the tags which differ, with at most one request each.
+func (c *Client) SetTorrentTags(tags []string, hashes ...string) error {
+	return c.SetTorrentTagsContext(context.Background(), tags, hashes...)
+}
+
+// SetTorrentTagsContext is like SetTorrentTags but uses ctx for the requests.
+func (c *Client) SetTorrentTagsContext(ctx context.Context, tags []string, hashes ...string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	// the server sends the hashes in lowercase
+	lower := make([]string, len(hashes))
+	for i, h := range hashes {
+		lower[i] = strings.ToLower(h)
+	}
+	hashes = lower
+	ts, err := c.TorrentListContext(ctx, Optional{
+		"hashes": strings.Join(hashes, "|"),
+	})
+	if err != nil {
+		return err
+	}
+	current := make(map[string][]string, len(ts))
+	for _, t := range ts {
+		current[strings.ToLower(t.Hash)] = t.Tags
+	}
+	for _, h := range hashes {
+		if _, ok := current[h]; !ok {
+			return fmt.Errorf("%w: torrent %s", ErrNotFound, h)
+		}
+	}
+	add, remove, extra := tagsDiff(current, tags)
+	// adding a tag twice or removing a missing one is a no-op,
+	// so every torrent can be sent the same tags
+	if len(add) > 0 {
+		err = c.AddTagsContext(ctx, tags, add...)
+		if err != nil {
+			return err
+		}
+	}
+	if len(remove) > 0 {
+		return c.RemoveTagsContext(ctx, extra, remove...)
+	}
+	return nil
+}
+
+// tagsDiff returns the torrents of current which miss some of tags,
+// the ones which have other tags, and these other tags.
+func tagsDiff(current map[string][]string, tags []string) (add, remove, extra []string) {
+	for h, cur := range current {
+		for _, tag := range tags {
+			if !containsStr(cur, tag) {
+				add = append(add, h)
+				break
+			}
+		}
+		removed := false
+		for _, tag := range cur {
+			if containsStr(tags, tag) {
+				continue
+			}
+			removed = true
+			if !containsStr(extra, tag) {
+				extra = append(extra, tag)
+			}
+		}
+		if removed {
+			remove = append(remove, h)
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(remove)
+	sort.Strings(extra)
+	return add, remove, extra
+}
@@ -4,9 +4,11 @@
 package qbt_apiv2
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -15,55 +17,56 @@ import (
 // Torrent holds a basic torrent object from qbittorrent
 // which is `sync/maindata` ,`torrents/info` returned
 type Torrent struct {
-	AddedOn           int     `json:"added_on"`
-	AmountLeft        int     `json:"amount_left"`
-	AutoTmm           bool    `json:"auto_tmm"`
-	Availability      float64 `json:"availability"`
-	Category          string  `json:"category"`
-	Completed         int     `json:"completed"`
-	CompletionOn      int     `json:"completion_on"`
-	ContentPath       string  `json:"content_path"`
-	DLLimit           int     `json:"dl_limit"`
-	Dlspeed           int     `json:"dlspeed"`
-	DownloadPath      string  `json:"download_path"`
-	Downloaded        int     `json:"downloaded"`
-	DownloadedSession int     `json:"downloaded_session"`
-	Eta               int     `json:"eta"`
-	FLPiecePrio       bool    `json:"f_l_piece_prio"`
-	ForceStart        bool    `json:"force_start"`
-	Hash              string  `json:"hash"`
-	InfohashV1        string  `json:"infohash_v1"`
-	InfohashV2        string  `json:"infohash_v2"`
-	LastActivity      int     `json:"last_activity"`
-	MagnetURI         string  `json:"magnet_uri"`
-	MaxRatio          float64 `json:"max_ratio"`
-	MaxSeedingTime    int     `json:"max_seeding_time"`
-	Name              string  `json:"name"`
-	NumComplete       int     `json:"num_complete"`
-	NumIncomplete     int     `json:"num_incomplete"`
-	NumLeechs         int     `json:"num_leechs"`
-	NumSeeds          int     `json:"num_seeds"`
-	Priority          int     `json:"priority"`
-	Progress          float64 `json:"progress"`
-	Ratio             float64 `json:"ratio"`
-	RatioLimit        float64 `json:"ratio_limit"`
-	SavePath          string  `json:"save_path"`
-	SeedingTime       int     `json:"seeding_time"`
-	SeedingTimeLimit  int     `json:"seeding_time_limit"`
-	SeenComplete      int     `json:"seen_complete"`
-	SeqDL             bool    `json:"seq_dl"`
-	Size              int     `json:"size"`
-	State             string  `json:"state"`
-	SuperSeeding      bool    `json:"super_seeding"`
-	Tags              string  `json:"tags"`
-	TimeActive        int     `json:"time_active"`
-	TotalSize         int     `json:"total_size"`
-	Tracker           string  `json:"tracker"`
-	TrackersCount     int     `json:"trackers_count"`
-	UpLimit           int     `json:"up_limit"`
-	Uploaded          int     `json:"uploaded"`
-	UploadedSession   int     `json:"uploaded_session"`
-	Upspeed           int     `json:"upspeed"`
+	AddedOn                  int     `json:"added_on"`
+	AmountLeft               int     `json:"amount_left"`
+	AutoTmm                  bool    `json:"auto_tmm"`
+	Availability             float64 `json:"availability"`
+	Category                 string  `json:"category"`
+	Completed                int     `json:"completed"`
+	CompletionOn             int     `json:"completion_on"`
+	ContentPath              string  `json:"content_path"`
+	DLLimit                  int     `json:"dl_limit"`
+	Dlspeed                  int     `json:"dlspeed"`
+	DownloadPath             string  `json:"download_path"`
+	Downloaded               int     `json:"downloaded"`
+	DownloadedSession        int     `json:"downloaded_session"`
+	Eta                      int     `json:"eta"`
+	FLPiecePrio              bool    `json:"f_l_piece_prio"`
+	ForceStart               bool    `json:"force_start"`
+	Hash                     string  `json:"hash"`
+	InactiveSeedingTimeLimit int     `json:"inactive_seeding_time_limit"`
+	InfohashV1               string  `json:"infohash_v1"`
+	InfohashV2               string  `json:"infohash_v2"`
+	LastActivity             int     `json:"last_activity"`
+	MagnetURI                string  `json:"magnet_uri"`
+	MaxRatio                 float64 `json:"max_ratio"`
+	MaxSeedingTime           int     `json:"max_seeding_time"`
+	Name                     string  `json:"name"`
+	NumComplete              int     `json:"num_complete"`
+	NumIncomplete            int     `json:"num_incomplete"`
+	NumLeechs                int     `json:"num_leechs"`
+	NumSeeds                 int     `json:"num_seeds"`
+	Priority                 int     `json:"priority"`
+	Progress                 float64 `json:"progress"`
+	Ratio                    float64 `json:"ratio"`
+	RatioLimit               float64 `json:"ratio_limit"`
+	SavePath                 string  `json:"save_path"`
+	SeedingTime              int     `json:"seeding_time"`
+	SeedingTimeLimit         int     `json:"seeding_time_limit"`
+	SeenComplete             int     `json:"seen_complete"`
+	SeqDL                    bool    `json:"seq_dl"`
+	Size                     int     `json:"size"`
+	State                    string  `json:"state"`
+	SuperSeeding             bool    `json:"super_seeding"`
+	Tags                     TagList `json:"tags"`
+	TimeActive               int     `json:"time_active"`
+	TotalSize                int     `json:"total_size"`
+	Tracker                  string  `json:"tracker"`
+	TrackersCount            int     `json:"trackers_count"`
+	UpLimit                  int     `json:"up_limit"`
+	Uploaded                 int     `json:"uploaded"`
+	UploadedSession          int     `json:"uploaded_session"`
+	Upspeed                  int     `json:"upspeed"`
 }
 
 // TorrentProp holds a torrent object from qbittorrent
@@ -106,10 +109,14 @@ type TorrentProp struct {
 
 // Tracker holds a tracker object from qbittorrent
 type Tracker struct {
-	Msg      string `json:"msg"`
-	NumPeers int    `json:"num_peers"`
-	Status   string `json:"status"`
-	URL      string `json:"url"`
+	Msg           string        `json:"msg"`
+	NumPeers      int           `json:"num_peers"`
+	NumSeeds      int           `json:"num_seeds"`
+	NumLeeches    int           `json:"num_leeches"`
+	NumDownloaded int           `json:"num_downloaded"`
+	Status        TrackerStatus `json:"status"`
+	Tier          TrackerTier   `json:"tier"`
+	URL           string        `json:"url"`
 }
 
 // WebSeed holds a webseed object from qbittorrent
@@ -119,28 +126,33 @@ type WebSeed struct {
 
 // TorrentFile holds a torrent file object from qbittorrent
 type TorrentFile struct {
-	IsSeed       bool    `json:"is_seed"`
-	Name         string  `json:"name"`
-	Priority     int     `json:"priority"`
-	Progress     float64 `json:"progress"`
-	Size         int     `json:"size"`
-	PieceRange   []int   `json:"piece_range"`
-	Availability float64 `json:"availability"`
+	IsSeed       bool         `json:"is_seed"`
+	Name         string       `json:"name"`
+	Priority     FilePriority `json:"priority"`
+	Progress     float64      `json:"progress"`
+	Size         int          `json:"size"`
+	PieceRange   []int        `json:"piece_range"`
+	Availability float64      `json:"availability"`
 }
 
 type File struct {
-	Availability float64 `json:"availability"`
-	Index        int     `json:"index"`
-	Name         string  `json:"name"`
-	PieceRange   []int   `json:"piece_range"`
-	Priority     int     `json:"priority"`
-	Progress     float64 `json:"progress"`
-	Size         int     `json:"size"`
-	IsSeed       bool    `json:"is_seed"`
+	Availability float64      `json:"availability"`
+	Index        int          `json:"index"`
+	Name         string       `json:"name"`
+	PieceRange   []int        `json:"piece_range"`
+	Priority     FilePriority `json:"priority"`
+	Progress     float64      `json:"progress"`
+	Size         int          `json:"size"`
+	IsSeed       bool         `json:"is_seed"`
 }
 
 func (c *Client) AddNewTorrent(opt Optional) error {
-	resp, err := c.postMultipartData("torrents/add", opt)
+	return c.AddNewTorrentContext(context.Background(), opt)
+}
+
+// AddNewTorrentContext is like AddNewTorrent but uses ctx for the request.
+func (c *Client) AddNewTorrentContext(ctx context.Context, opt Optional) error {
+	resp, err := c.postMultipartData(ctx, "torrents/add", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -152,6 +164,11 @@ func (c *Client) AddNewTorrent(opt Optional) error {
 }
 
 func (c *Client) AddNewTorrentViaUrl(url, path string, tags ...string) error {
+	return c.AddNewTorrentViaUrlContext(context.Background(), url, path, tags...)
+}
+
+// AddNewTorrentViaUrlContext is like AddNewTorrentViaUrl but uses ctx for the request.
+func (c *Client) AddNewTorrentViaUrlContext(ctx context.Context, url, path string, tags ...string) error {
 	ap, err := filepath.Abs(path)
 	if err != nil {
 		return fmt.Errorf("cannot conv abs_path: %s: %w ", path, err)
@@ -168,12 +185,17 @@ func (c *Client) AddNewTorrentViaUrl(url, path string, tags ...string) error {
 		ts = ts[:len(ts)-1]
 		opt["tags"] = ts
 	}
-	err = c.AddNewTorrent(opt)
+	err = c.AddNewTorrentContext(ctx, opt)
 	return err
 }
 
 func (c *Client) TorrentList(opt Optional) ([]Torrent, error) {
-	resp, err := c.postXwwwFormUrlencoded("torrents/info", opt)
+	return c.TorrentListContext(context.Background(), opt)
+}
+
+// TorrentListContext is like TorrentList but uses ctx for the request.
+func (c *Client) TorrentListContext(ctx context.Context, opt Optional) ([]Torrent, error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/info", opt)
 
 	err = RespOk(resp, err)
 	if err != nil {
@@ -193,7 +215,12 @@ func (c *Client) TorrentList(opt Optional) ([]Torrent, error) {
 }
 
 func (c *Client) GetTorrentProperties(hash string) (TorrentProp, error) {
-	resp, err := c.postXwwwFormUrlencoded("torrents/properties", Optional{
+	return c.GetTorrentPropertiesContext(context.Background(), hash)
+}
+
+// GetTorrentPropertiesContext is like GetTorrentProperties but uses ctx for the request.
+func (c *Client) GetTorrentPropertiesContext(ctx context.Context, hash string) (TorrentProp, error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/properties", Optional{
 		"hash": hash,
 	})
 	err = RespOk(resp, err)
@@ -214,6 +241,11 @@ func (c *Client) GetTorrentProperties(hash string) (TorrentProp, error) {
 }
 
 func (c *Client) GetTorrentContents(hash string, indexes ...int) ([]TorrentFile, error) {
+	return c.GetTorrentContentsContext(context.Background(), hash, indexes...)
+}
+
+// GetTorrentContentsContext is like GetTorrentContents but uses ctx for the request.
+func (c *Client) GetTorrentContentsContext(ctx context.Context, hash string, indexes ...int) ([]TorrentFile, error) {
 	opt := Optional{
 		"hash": hash,
 	}
@@ -226,7 +258,7 @@ func (c *Client) GetTorrentContents(hash string, indexes ...int) ([]TorrentFile,
 		opt["indexes"] = idxes
 	}
 
-	resp, err := c.postXwwwFormUrlencoded("torrents/files", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/files", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return nil, err
@@ -245,12 +277,17 @@ func (c *Client) GetTorrentContents(hash string, indexes ...int) ([]TorrentFile,
 }
 
 func (c *Client) DelTorrents(delfile bool, hashes ...string) error {
+	return c.DelTorrentsContext(context.Background(), delfile, hashes...)
+}
+
+// DelTorrentsContext is like DelTorrents but uses ctx for the request.
+func (c *Client) DelTorrentsContext(ctx context.Context, delfile bool, hashes ...string) error {
 	hs := strings.Join(hashes, "|")
 	opt := Optional{
 		"hashes":      hs,
 		"deleteFiles": delfile,
 	}
-	resp, err := c.postXwwwFormUrlencoded("torrents/delete", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/delete", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -260,15 +297,25 @@ func (c *Client) DelTorrents(delfile bool, hashes ...string) error {
 }
 
 func (c *Client) DelTorrentsFs(hashes ...string) error {
-	return c.DelTorrents(true, hashes...)
+	return c.DelTorrentsFsContext(context.Background(), hashes...)
+}
+
+// DelTorrentsFsContext is like DelTorrentsFs but uses ctx for the request.
+func (c *Client) DelTorrentsFsContext(ctx context.Context, hashes ...string) error {
+	return c.DelTorrentsContext(ctx, true, hashes...)
 }
 
 func (c *Client) DelTags(tags ...string) error {
+	return c.DelTagsContext(context.Background(), tags...)
+}
+
+// DelTagsContext is like DelTags but uses ctx for the request.
+func (c *Client) DelTagsContext(ctx context.Context, tags ...string) error {
 	ts := strings.Join(tags, ",")
 	opt := Optional{
 		"tags": ts,
 	}
-	resp, err := c.postXwwwFormUrlencoded("torrents/deleteTags", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/deleteTags", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -278,12 +325,17 @@ func (c *Client) DelTags(tags ...string) error {
 }
 
 func (c *Client) RenameFile(hash, old, new string) error {
+	return c.RenameFileContext(context.Background(), hash, old, new)
+}
+
+// RenameFileContext is like RenameFile but uses ctx for the request.
+func (c *Client) RenameFileContext(ctx context.Context, hash, old, new string) error {
 	opt := Optional{
 		"hash":    hash,
 		"oldPath": old,
 		"newPath": new,
 	}
-	resp, err := c.postXwwwFormUrlencoded("torrents/renameFile", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/renameFile", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -292,28 +344,133 @@ func (c *Client) RenameFile(hash, old, new string) error {
 	return nil
 }
 
+// SetLocation moves the data of torrents to location, the returned error matches
+// ErrEmptyPath, ErrPathNotWritable or ErrCreateDirFailed when the server refuses it.
 func (c *Client) SetLocation(location string, hashes ...string) error {
-	hs := strings.Join(hashes, "|")
-	opt := Optional{
-		"hashes":   hs,
+	return c.SetLocationContext(context.Background(), location, hashes...)
+}
+
+// SetLocationContext is like SetLocation but uses ctx for the request.
+func (c *Client) SetLocationContext(ctx context.Context, location string, hashes ...string) error {
+	err := c.postAction(ctx, "torrents/setLocation", Optional{
+		"hashes":   strings.Join(hashes, "|"),
 		"location": location,
+	})
+	return pathErr(err)
+}
+
+// pathErr maps the status codes of the path endpoints to their errors.
+func pathErr(err error) error {
+	err = withStatusErr(err, http.StatusBadRequest, ErrEmptyPath)
+	err = withStatusErr(err, http.StatusForbidden, ErrPathNotWritable)
+	return withStatusErr(err, http.StatusConflict, ErrCreateDirFailed)
+}
+
+// SetSavePath sets the save path of torrents, it requires qBittorrent v4.4.0 or later.
+// The returned error matches ErrEmptyPath, ErrPathNotWritable or ErrCreateDirFailed
+// when the server refuses the path.
+func (c *Client) SetSavePath(path string, hashes ...string) error {
+	return c.SetSavePathContext(context.Background(), path, hashes...)
+}
+
+// SetSavePathContext is like SetSavePath but uses ctx for the request.
+func (c *Client) SetSavePathContext(ctx context.Context, path string, hashes ...string) error {
+	if err := c.requireAPI(ctx, "torrents/setSavePath", apiSavePath); err != nil {
+		return err
 	}
-	resp, err := c.postXwwwFormUrlencoded("torrents/setLocation", opt)
-	err = RespOk(resp, err)
-	if err != nil {
+	err := c.postAction(ctx, "torrents/setSavePath", Optional{
+		"id":   strings.Join(hashes, "|"),
+		"path": path,
+	})
+	return pathErr(err)
+}
+
+// SetDownloadPath sets the path of the incomplete torrents, it requires
+// qBittorrent v4.4.0 or later and fails like SetSavePath.
+func (c *Client) SetDownloadPath(path string, hashes ...string) error {
+	return c.SetDownloadPathContext(context.Background(), path, hashes...)
+}
+
+// SetDownloadPathContext is like SetDownloadPath but uses ctx for the request.
+func (c *Client) SetDownloadPathContext(ctx context.Context, path string, hashes ...string) error {
+	if err := c.requireAPI(ctx, "torrents/setDownloadPath", apiSavePath); err != nil {
 		return err
 	}
-	ignrBody(resp.Body)
-	return nil
+	err := c.postAction(ctx, "torrents/setDownloadPath", Optional{
+		"id":   strings.Join(hashes, "|"),
+		"path": path,
+	})
+	return pathErr(err)
+}
+
+// RenameTorrent sets the name of a torrent.
+func (c *Client) RenameTorrent(hash, name string) error {
+	return c.RenameTorrentContext(context.Background(), hash, name)
+}
+
+// RenameTorrentContext is like RenameTorrent but uses ctx for the request.
+func (c *Client) RenameTorrentContext(ctx context.Context, hash, name string) error {
+	return c.postAction(ctx, "torrents/rename", Optional{
+		"hash": hash,
+		"name": name,
+	})
+}
+
+// SetComment sets the comment of torrents, it requires qBittorrent v5.0.0
+// or later and returns an error matching ErrNotSupported otherwise.
+func (c *Client) SetComment(comment string, hashes ...string) error {
+	return c.SetCommentContext(context.Background(), comment, hashes...)
+}
+
+// SetCommentContext is like SetComment but uses ctx for the request.
+func (c *Client) SetCommentContext(ctx context.Context, comment string, hashes ...string) error {
+	if err := c.requireAPI(ctx, "torrents/setComment", apiComment); err != nil {
+		return err
+	}
+	return c.postAction(ctx, "torrents/setComment", Optional{
+		"hashes":  strings.Join(hashes, "|"),
+		"comment": comment,
+	})
+}
+
+// SetAutoManagement enables or disables the automatic torrent management of torrents.
+func (c *Client) SetAutoManagement(enable bool, hashes ...string) error {
+	return c.SetAutoManagementContext(context.Background(), enable, hashes...)
+}
+
+// SetAutoManagementContext is like SetAutoManagement but uses ctx for the request.
+func (c *Client) SetAutoManagementContext(ctx context.Context, enable bool, hashes ...string) error {
+	return c.postAction(ctx, "torrents/setAutoManagement", Optional{
+		"hashes": strings.Join(hashes, "|"),
+		"enable": enable,
+	})
+}
+
+// SetSuperSeeding enables or disables the super seeding of torrents.
+func (c *Client) SetSuperSeeding(value bool, hashes ...string) error {
+	return c.SetSuperSeedingContext(context.Background(), value, hashes...)
+}
+
+// SetSuperSeedingContext is like SetSuperSeeding but uses ctx for the request.
+func (c *Client) SetSuperSeedingContext(ctx context.Context, value bool, hashes ...string) error {
+	return c.postAction(ctx, "torrents/setSuperSeeding", Optional{
+		"hashes": strings.Join(hashes, "|"),
+		"value":  value,
+	})
 }
 
 func (c *Client) RenameFolder(hash, old, new string) error {
+	return c.RenameFolderContext(context.Background(), hash, old, new)
+}
+
+// RenameFolderContext is like RenameFolder but uses ctx for the request.
+func (c *Client) RenameFolderContext(ctx context.Context, hash, old, new string) error {
 	opt := Optional{
 		"hash":    hash,
 		"oldPath": old,
 		"newPath": new,
 	}
-	resp, err := c.postXwwwFormUrlencoded("torrents/renameFolder", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/renameFolder", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -323,13 +480,18 @@ func (c *Client) RenameFolder(hash, old, new string) error {
 }
 
 func (c *Client) AddCategory(categoryName, savePath string) error {
+	return c.AddCategoryContext(context.Background(), categoryName, savePath)
+}
+
+// AddCategoryContext is like AddCategory but uses ctx for the request.
+func (c *Client) AddCategoryContext(ctx context.Context, categoryName, savePath string) error {
 	opt := Optional{
 		"category": categoryName,
 	}
 	if savePath != "" {
 		opt["savePath"] = savePath
 	}
-	resp, err := c.postXwwwFormUrlencoded("torrents/createCategory", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/createCategory", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -339,11 +501,16 @@ func (c *Client) AddCategory(categoryName, savePath string) error {
 }
 
 func (c *Client) RmCategoies(categories ...string) error {
+	return c.RmCategoiesContext(context.Background(), categories...)
+}
+
+// RmCategoiesContext is like RmCategoies but uses ctx for the request.
+func (c *Client) RmCategoiesContext(ctx context.Context, categories ...string) error {
 	categs := strings.Join(categories, "\n")
 	opt := Optional{
 		"categories": categs,
 	}
-	resp, err := c.postXwwwFormUrlencoded("torrents/removeCategories", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/removeCategories", opt)
 	err = RespOk(resp, err)
 	if err != nil {
 		return err
@@ -353,6 +520,11 @@ func (c *Client) RmCategoies(categories ...string) error {
 }
 
 func (c *Client) Files(hash string, indexs ...string) ([]File, error) {
+	return c.FilesContext(context.Background(), hash, indexs...)
+}
+
+// FilesContext is like Files but uses ctx for the request.
+func (c *Client) FilesContext(ctx context.Context, hash string, indexs ...string) ([]File, error) {
 	idxs := ""
 	if len(indexs) != 0 {
 		idxs = strings.Join(indexs, "|")
@@ -364,10 +536,12 @@ func (c *Client) Files(hash string, indexs ...string) ([]File, error) {
 	if idxs != "" {
 		opt["indexes"] = idxs
 	}
-	resp, err := c.postXwwwFormUrlencoded("torrents/files", opt)
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/files", opt)
+	err = RespOk(resp, err)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -379,3 +553,225 @@ func (c *Client) Files(hash string, indexs ...string) ([]File, error) {
 	}
 	return fs, nil
 }
+
+// AllTorrents can be passed as the only hash to select every torrent.
+const AllTorrents = "all"
+
+// postAction performs a request whose response body carries no information.
+func (c *Client) postAction(ctx context.Context, endpoint string, opt Optional) error {
+	resp, err := c.postXwwwFormUrlencoded(ctx, endpoint, opt)
+	err = RespOk(resp, err)
+	if err != nil {
+		return err
+	}
+	ignrBody(resp.Body)
+	return nil
+}
+
+// versioned returns the endpoint for the web API version of the server,
+// newName if it is at least since, otherwise oldName.
+func (c *Client) versioned(ctx context.Context, since apiVersion, newName, oldName string) (string, error) {
+	v, err := c.apiVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	if v.atLeast(since) {
+		return newName, nil
+	}
+	return oldName, nil
+}
+
+// StopTorrents stops (pauses) torrents, it calls `torrents/stop`
+// or `torrents/pause` on servers older than qBittorrent v5.0.0.
+func (c *Client) StopTorrents(hashes ...string) error {
+	return c.StopTorrentsContext(context.Background(), hashes...)
+}
+
+// StopTorrentsContext is like StopTorrents but uses ctx for the request.
+func (c *Client) StopTorrentsContext(ctx context.Context, hashes ...string) error {
+	endpoint, err := c.versioned(ctx, apiStopStart, "torrents/stop", "torrents/pause")
+	if err != nil {
+		return err
+	}
+	return c.postAction(ctx, endpoint, Optional{
+		"hashes": strings.Join(hashes, "|"),
+	})
+}
+
+// StartTorrents starts (resumes) torrents, it calls `torrents/start`
+// or `torrents/resume` on servers older than qBittorrent v5.0.0.
+func (c *Client) StartTorrents(hashes ...string) error {
+	return c.StartTorrentsContext(context.Background(), hashes...)
+}
+
+// StartTorrentsContext is like StartTorrents but uses ctx for the request.
+func (c *Client) StartTorrentsContext(ctx context.Context, hashes ...string) error {
+	endpoint, err := c.versioned(ctx, apiStopStart, "torrents/start", "torrents/resume")
+	if err != nil {
+		return err
+	}
+	return c.postAction(ctx, endpoint, Optional{
+		"hashes": strings.Join(hashes, "|"),
+	})
+}
+
+// PauseTorrents is the name of StopTorrents before qBittorrent v5.0.0.
+func (c *Client) PauseTorrents(hashes ...string) error {
+	return c.StopTorrentsContext(context.Background(), hashes...)
+}
+
+// PauseTorrentsContext is like PauseTorrents but uses ctx for the request.
+func (c *Client) PauseTorrentsContext(ctx context.Context, hashes ...string) error {
+	return c.StopTorrentsContext(ctx, hashes...)
+}
+
+// ResumeTorrents is the name of StartTorrents before qBittorrent v5.0.0.
+func (c *Client) ResumeTorrents(hashes ...string) error {
+	return c.StartTorrentsContext(context.Background(), hashes...)
+}
+
+// ResumeTorrentsContext is like ResumeTorrents but uses ctx for the request.
+func (c *Client) ResumeTorrentsContext(ctx context.Context, hashes ...string) error {
+	return c.StartTorrentsContext(ctx, hashes...)
+}
+
+// RecheckTorrents rechecks the downloaded data of torrents.
+func (c *Client) RecheckTorrents(hashes ...string) error {
+	return c.RecheckTorrentsContext(context.Background(), hashes...)
+}
+
+// RecheckTorrentsContext is like RecheckTorrents but uses ctx for the request.
+func (c *Client) RecheckTorrentsContext(ctx context.Context, hashes ...string) error {
+	return c.postAction(ctx, "torrents/recheck", Optional{
+		"hashes": strings.Join(hashes, "|"),
+	})
+}
+
+// ReannounceTorrents reannounces torrents to their trackers.
+func (c *Client) ReannounceTorrents(hashes ...string) error {
+	return c.ReannounceTorrentsContext(context.Background(), hashes...)
+}
+
+// ReannounceTorrentsContext is like ReannounceTorrents but uses ctx for the request.
+func (c *Client) ReannounceTorrentsContext(ctx context.Context, hashes ...string) error {
+	return c.postAction(ctx, "torrents/reannounce", Optional{
+		"hashes": strings.Join(hashes, "|"),
+	})
+}
+
+// SetForceStart enables or disables force start of torrents.
+func (c *Client) SetForceStart(value bool, hashes ...string) error {
+	return c.SetForceStartContext(context.Background(), value, hashes...)
+}
+
+// SetForceStartContext is like SetForceStart but uses ctx for the request.
+func (c *Client) SetForceStartContext(ctx context.Context, value bool, hashes ...string) error {
+	return c.postAction(ctx, "torrents/setForceStart", Optional{
+		"hashes": strings.Join(hashes, "|"),
+		"value":  value,
+	})
+}
+
+// ToggleSequentialDownload toggles the sequential download of torrents.
+func (c *Client) ToggleSequentialDownload(hashes ...string) error {
+	return c.ToggleSequentialDownloadContext(context.Background(), hashes...)
+}
+
+// ToggleSequentialDownloadContext is like ToggleSequentialDownload but uses ctx for the request.
+func (c *Client) ToggleSequentialDownloadContext(ctx context.Context, hashes ...string) error {
+	return c.postAction(ctx, "torrents/toggleSequentialDownload", Optional{
+		"hashes": strings.Join(hashes, "|"),
+	})
+}
+
+// ToggleFirstLastPiecePrio toggles the first and last piece priority of torrents.
+func (c *Client) ToggleFirstLastPiecePrio(hashes ...string) error {
+	return c.ToggleFirstLastPiecePrioContext(context.Background(), hashes...)
+}
+
+// ToggleFirstLastPiecePrioContext is like ToggleFirstLastPiecePrio but uses ctx for the request.
+func (c *Client) ToggleFirstLastPiecePrioContext(ctx context.Context, hashes ...string) error {
+	return c.postAction(ctx, "torrents/toggleFirstLastPiecePrio", Optional{
+		"hashes": strings.Join(hashes, "|"),
+	})
+}
+
+// GetWebSeeds returns the web seeds of a torrent.
+func (c *Client) GetWebSeeds(hash string) ([]WebSeed, error) {
+	return c.GetWebSeedsContext(context.Background(), hash)
+}
+
+// GetWebSeedsContext is like GetWebSeeds but uses ctx for the request.
+func (c *Client) GetWebSeedsContext(ctx context.Context, hash string) ([]WebSeed, error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/webseeds", Optional{
+		"hash": hash,
+	})
+	err = RespOk(resp, err)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var ws []WebSeed
+	err = json.Unmarshal(b, &ws)
+	if err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
+
+// AddWebSeeds adds web seeds to a torrent, it requires qBittorrent v5.0.0 or later
+// and returns an error matching ErrNotSupported otherwise.
+func (c *Client) AddWebSeeds(hash string, urls ...string) error {
+	return c.AddWebSeedsContext(context.Background(), hash, urls...)
+}
+
+// AddWebSeedsContext is like AddWebSeeds but uses ctx for the request.
+func (c *Client) AddWebSeedsContext(ctx context.Context, hash string, urls ...string) error {
+	if err := c.requireAPI(ctx, "torrents/addWebSeeds", apiWebSeeds); err != nil {
+		return err
+	}
+	return c.postAction(ctx, "torrents/addWebSeeds", Optional{
+		"hash": hash,
+		"urls": strings.Join(urls, "|"),
+	})
+}
+
+// EditWebSeed replaces the web seed origURL of a torrent with newURL,
+// it requires qBittorrent v5.0.0 or later and returns an error matching
+// ErrNotSupported otherwise.
+func (c *Client) EditWebSeed(hash, origURL, newURL string) error {
+	return c.EditWebSeedContext(context.Background(), hash, origURL, newURL)
+}
+
+// EditWebSeedContext is like EditWebSeed but uses ctx for the request.
+func (c *Client) EditWebSeedContext(ctx context.Context, hash, origURL, newURL string) error {
+	if err := c.requireAPI(ctx, "torrents/editWebSeed", apiWebSeeds); err != nil {
+		return err
+	}
+	return c.postAction(ctx, "torrents/editWebSeed", Optional{
+		"hash":    hash,
+		"origUrl": origURL,
+		"newUrl":  newURL,
+	})
+}
+
+// RemoveWebSeeds removes web seeds from a torrent, it requires qBittorrent v5.0.0
+// or later and returns an error matching ErrNotSupported otherwise.
+func (c *Client) RemoveWebSeeds(hash string, urls ...string) error {
+	return c.RemoveWebSeedsContext(context.Background(), hash, urls...)
+}
+
+// RemoveWebSeedsContext is like RemoveWebSeeds but uses ctx for the request.
+func (c *Client) RemoveWebSeedsContext(ctx context.Context, hash string, urls ...string) error {
+	if err := c.requireAPI(ctx, "torrents/removeWebSeeds", apiWebSeeds); err != nil {
+		return err
+	}
+	return c.postAction(ctx, "torrents/removeWebSeeds", Optional{
+		"hash": hash,
+		"urls": strings.Join(urls, "|"),
+	})
+}
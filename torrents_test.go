@@ -0,0 +1,292 @@
+package qbt_apiv2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestStopStartEndpoints(t *testing.T) {
+	for _, tt := range []struct {
+		apiVersion  string
+		stop, start string
+	}{
+		{"2.8.3", "torrents/pause", "torrents/resume"},
+		{"2.11.0", "torrents/stop", "torrents/start"},
+		{"2.11.2", "torrents/stop", "torrents/start"},
+	} {
+		srv := newFakeServer(t)
+		srv.reply("app/webapiVersion", tt.apiVersion)
+		var hashes string
+		for _, ep := range []string{tt.stop, tt.start} {
+			srv.handle(ep, func(w http.ResponseWriter, r *http.Request) {
+				hashes = r.FormValue("hashes")
+			})
+		}
+		cli := srv.client(t)
+		if err := cli.StopTorrents("aaa", "bbb"); err != nil {
+			t.Fatal(err)
+		}
+		if hashes != "aaa|bbb" || srv.called(tt.stop) != 1 {
+			t.Errorf("%s: stop did not call %s with the hashes", tt.apiVersion, tt.stop)
+		}
+		if err := cli.ResumeTorrents(AllTorrents); err != nil {
+			t.Fatal(err)
+		}
+		if hashes != "all" || srv.called(tt.start) != 1 {
+			t.Errorf("%s: resume did not call %s for all torrents", tt.apiVersion, tt.start)
+		}
+		if n := srv.called("app/webapiVersion"); n != 1 {
+			t.Errorf("%s: web API version requested %d times, want it cached", tt.apiVersion, n)
+		}
+	}
+}
+
+func TestGetTorrentTrackers(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("torrents/trackers", `[
+		{"url":"** [DHT] **","status":0,"tier":"","num_peers":12,"msg":""},
+		{"url":"https://t.example/announce?passkey=old","status":2,"tier":0,"num_peers":3,"num_seeds":10,"num_leeches":1,"num_downloaded":99,"msg":""},
+		{"url":"udp://open.example:1337","status":4,"tier":1,"num_peers":-1,"msg":"timed out"}]`)
+	cli := srv.client(t)
+	ts, err := cli.GetTorrentTrackers("aaa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 3 || !ts[0].IsSpecial() || ts[0].Tier != -1 ||
+		ts[1].Status != TrackerWorking || ts[1].NumDownloaded != 99 ||
+		ts[2].Status != TrackerNotWorking || ts[2].Tier != 1 || ts[2].Status.String() != "Not working" {
+		t.Errorf("unexpected trackers: %+v", ts)
+	}
+}
+
+func TestReplaceTrackerPrefix(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("torrents/info", `[{"hash":"aaa"},{"hash":"bbb"}]`)
+	srv.handle("torrents/trackers", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("hash") == "bbb" {
+			w.Write([]byte(`[{"url":"https://t.example/old/announce","tier":0}]`))
+			return
+		}
+		w.Write([]byte(`[{"url":"** [DHT] **","tier":""},
+			{"url":"https://t.example/old/announce","tier":0},
+			{"url":"https://t.example/old/scrape","tier":0},
+			{"url":"udp://open.example:1337","tier":1}]`))
+	})
+	var edits []string
+	srv.handle("torrents/editTracker", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("hash") == "bbb" {
+			http.Error(w, "", http.StatusConflict)
+			return
+		}
+		edits = append(edits, r.FormValue("hash")+" "+r.FormValue("origUrl")+" "+r.FormValue("newUrl"))
+	})
+	cli := srv.client(t)
+	n, err := cli.ReplaceTrackerPrefix("https://t.example/old/", "https://t.example/new/")
+	if n != 2 || !errors.Is(err, ErrConflict) {
+		t.Fatalf("ReplaceTrackerPrefix = %d, %v", n, err)
+	}
+	want := []string{
+		"aaa https://t.example/old/announce https://t.example/new/announce",
+		"aaa https://t.example/old/scrape https://t.example/new/scrape",
+	}
+	if fmt.Sprint(edits) != fmt.Sprint(want) {
+		t.Errorf("edits = %q", edits)
+	}
+	if srv.called("sync/maindata") != 0 {
+		t.Error("the maindata snapshot of the session was reset")
+	}
+}
+
+func TestWebSeedsVersionGate(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("app/webapiVersion", "2.9.3")
+	srv.reply("torrents/webseeds", `[{"url":"https://mirror.example/ubuntu.iso"}]`)
+	cli := srv.client(t)
+	ws, err := cli.GetWebSeeds("aaa")
+	if err != nil || len(ws) != 1 || ws[0].URL != "https://mirror.example/ubuntu.iso" {
+		t.Fatalf("GetWebSeeds = %v, %v", ws, err)
+	}
+	err = cli.AddWebSeeds("aaa", "https://mirror2.example/ubuntu.iso")
+	if !errors.Is(err, ErrNotSupported) || srv.called("torrents/addWebSeeds") != 0 {
+		t.Fatalf("AddWebSeeds on an old server: %v", err)
+	}
+}
+
+func TestShareLimits(t *testing.T) {
+	for _, tt := range []struct {
+		apiVersion string
+		inactive   string
+	}{
+		{"2.9.2", ""},
+		{"2.9.3", "-2"},
+	} {
+		srv := newFakeServer(t)
+		srv.reply("app/webapiVersion", tt.apiVersion)
+		var form map[string][]string
+		srv.handle("torrents/setShareLimits", func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm
+		})
+		cli := srv.client(t)
+		err := cli.SetShareLimits(ShareLimits{
+			RatioLimit:               1.5,
+			SeedingTimeLimit:         TimeUnlimited,
+			InactiveSeedingTimeLimit: TimeGlobal,
+		}, "aaa", "bbb")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := fmt.Sprint(form["hashes"], form["ratioLimit"], form["seedingTimeLimit"], form["inactiveSeedingTimeLimit"])
+		want := fmt.Sprint([]string{"aaa|bbb"}, []string{"1.5"}, []string{"-1"}, []string(nil))
+		if tt.inactive != "" {
+			want = fmt.Sprint([]string{"aaa|bbb"}, []string{"1.5"}, []string{"-1"}, []string{tt.inactive})
+		}
+		if got != want {
+			t.Errorf("%s: setShareLimits form %s, want %s", tt.apiVersion, got, want)
+		}
+	}
+}
+
+func TestGetDownloadLimits(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("torrents/downloadLimit", `{"aaa":1024,"bbb":-1,"ccc":0}`)
+	cli := srv.client(t)
+	limits, err := cli.GetDownloadLimits("aaa", "bbb", "ccc")
+	if err != nil || limits["aaa"] != 1024 || limits["bbb"] != SpeedUnlimited || limits["ccc"] != SpeedUnlimited {
+		t.Errorf("GetDownloadLimits = %v, %v", limits, err)
+	}
+}
+
+func TestSetTorrentTags(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.handle("torrents/info", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("hashes") != "aaa|bbb|ccc" {
+			t.Errorf("torrents/info hashes = %q", r.FormValue("hashes"))
+		}
+		w.Write([]byte(`[{"hash":"aaa","tags":"linux, iso"},{"hash":"bbb","tags":"linux"},{"hash":"ccc","tags":"iso, old, linux"}]`))
+	})
+	var calls []string
+	for _, ep := range []string{"torrents/addTags", "torrents/removeTags"} {
+		srv.handle(ep, func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, r.URL.Path[len("/api/v2/"):]+" "+r.FormValue("tags")+" "+r.FormValue("hashes"))
+		})
+	}
+	cli := srv.client(t)
+	if err := cli.SetTorrentTags([]string{"linux", "iso"}, "aaa", "BBB", "ccc"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"torrents/addTags linux,iso bbb", "torrents/removeTags old ccc"}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %q", calls)
+	}
+	var tr Torrent
+	if err := json.Unmarshal([]byte(`{"tags":"a, b c,"}`), &tr); err != nil || fmt.Sprint(tr.Tags) != "[a b c]" {
+		t.Errorf("tags = %q, %v", tr.Tags, err)
+	}
+	if b, _ := json.Marshal(TagList{"a", "b"}); string(b) != `"a, b"` {
+		t.Errorf("TagList marshals to %s", b)
+	}
+}
+
+func TestPathErrors(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("app/webapiVersion", "2.11.0")
+	var form url.Values
+	status := map[string]int{"": http.StatusOK, "/full": http.StatusConflict, "/ro": http.StatusForbidden}
+	for _, ep := range []string{"torrents/setLocation", "torrents/setSavePath", "torrents/setDownloadPath"} {
+		srv.handle(ep, func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm
+			p := r.FormValue("location") + r.FormValue("path")
+			if p == "" {
+				http.Error(w, "Save path is empty", http.StatusBadRequest)
+				return
+			}
+			if code := status[strings.TrimPrefix(p, "/data")]; code != http.StatusOK {
+				http.Error(w, "", code)
+			}
+		})
+	}
+	cli := srv.client(t)
+	if err := cli.SetSavePath("/data", "aaa", "bbb"); err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("id") != "aaa|bbb" || form.Get("path") != "/data" {
+		t.Errorf("setSavePath form = %v", form)
+	}
+	for _, tt := range []struct {
+		path      string
+		err, code error
+	}{
+		{"", ErrEmptyPath, ErrBadRequest},
+		{"/data/ro", ErrPathNotWritable, ErrForbidden},
+		{"/data/full", ErrCreateDirFailed, ErrConflict},
+	} {
+		for name, set := range map[string]func(string, ...string) error{
+			"SetLocation":     cli.SetLocation,
+			"SetSavePath":     cli.SetSavePath,
+			"SetDownloadPath": cli.SetDownloadPath,
+		} {
+			if err := set(tt.path, "aaa"); !errors.Is(err, tt.err) || !errors.Is(err, tt.code) {
+				t.Errorf("%s(%q) = %v, want %v", name, tt.path, err, tt.err)
+			}
+		}
+	}
+}
+
+func TestSetCommentVersionGate(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("app/webapiVersion", "2.9.3")
+	srv.reply("torrents/rename", "")
+	cli := srv.client(t)
+	if err := cli.RenameTorrent("aaa", "name"); err != nil {
+		t.Fatal(err)
+	}
+	err := cli.SetComment("comment", "aaa")
+	if !errors.Is(err, ErrNotSupported) || srv.called("torrents/setComment") != 0 {
+		t.Fatalf("SetComment on an old server: %v", err)
+	}
+}
+
+func TestPathErrorsForbidden(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.reply("app/webapiVersion", "2.11.0")
+	for _, ep := range []string{"torrents/setLocation", "torrents/setSavePath", "torrents/setDownloadPath"} {
+		srv.handle(ep, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Your IP address has been banned after too many failed authentication attempts.", http.StatusForbidden)
+		})
+	}
+	cli := srv.client(t)
+	for name, set := range map[string]func(string, ...string) error{
+		"SetLocation":     cli.SetLocation,
+		"SetSavePath":     cli.SetSavePath,
+		"SetDownloadPath": cli.SetDownloadPath,
+	} {
+		if err := set("/data", "aaa"); !errors.Is(err, ErrIPBanned) || errors.Is(err, ErrPathNotWritable) {
+			t.Errorf("%s on a banned ip = %v", name, err)
+		}
+	}
+
+	// the session has no credentials to login again after Logout
+	srv = newFakeServer(t)
+	ss := new(sessions)
+	srv.handle("auth/login", ss.login)
+	srv.handle("auth/logout", ss.guard(ss.logout))
+	srv.handle("app/webapiVersion", ss.guard(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("2.11.0"))
+	}))
+	srv.handle("torrents/setLocation", ss.guard(func(w http.ResponseWriter, r *http.Request) {}))
+	cli = srv.client(t, WithAuth("admin", "adminadmin"))
+	if err := cli.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	err := cli.SetLocation("/data", "aaa")
+	if !errors.Is(err, ErrNotLoggedIn) || !errors.Is(err, ErrForbidden) || errors.Is(err, ErrPathNotWritable) {
+		t.Errorf("SetLocation after logout = %v", err)
+	}
+}
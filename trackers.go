@@ -0,0 +1,201 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TrackerStatus is the status of a tracker returned by `torrents/trackers`
+type TrackerStatus int
+
+const (
+	TrackerDisabled     TrackerStatus = iota // used for the DHT, PeX and LSD entries
+	TrackerNotContacted                      // not contacted yet
+	TrackerWorking                           // contacted and working
+	TrackerUpdating                          // updating
+	TrackerNotWorking                        // contacted but not working or not sending proper replies
+	TrackerError                             // the tracker replied with an error, qBittorrent v5.0.0+
+	TrackerUnreachable                       // the tracker could not be reached, qBittorrent v5.0.0+
+)
+
+func (s TrackerStatus) String() string {
+	switch s {
+	case TrackerDisabled:
+		return "Disabled"
+	case TrackerNotContacted:
+		return "Not contacted"
+	case TrackerWorking:
+		return "Working"
+	case TrackerUpdating:
+		return "Updating"
+	case TrackerNotWorking:
+		return "Not working"
+	case TrackerError:
+		return "Tracker error"
+	case TrackerUnreachable:
+		return "Unreachable"
+	default:
+		return "Unknown"
+	}
+}
+
+// TrackerTier is the tier of a tracker, it is negative for the DHT, PeX and LSD entries.
+type TrackerTier int
+
+// UnmarshalJSON accepts the empty string older servers send for the DHT, PeX and LSD entries.
+func (t *TrackerTier) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" {
+		*t = -1
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*t = TrackerTier(n)
+	return nil
+}
+
+// IsSpecial reports whether the entry is not a real tracker but DHT, PeX or LSD.
+func (t Tracker) IsSpecial() bool {
+	return strings.HasPrefix(t.URL, "** [")
+}
+
+// GetTorrentTrackers returns the trackers of a torrent,
+// including the DHT, PeX and LSD entries.
+func (c *Client) GetTorrentTrackers(hash string) ([]Tracker, error) {
+	return c.GetTorrentTrackersContext(context.Background(), hash)
+}
+
+// GetTorrentTrackersContext is like GetTorrentTrackers but uses ctx for the request.
+func (c *Client) GetTorrentTrackersContext(ctx context.Context, hash string) ([]Tracker, error) {
+	resp, err := c.postXwwwFormUrlencoded(ctx, "torrents/trackers", Optional{
+		"hash": hash,
+	})
+	err = RespOk(resp, err)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var ts []Tracker
+	err = json.Unmarshal(b, &ts)
+	if err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// AddTrackers adds trackers to a torrent.
+func (c *Client) AddTrackers(hash string, urls ...string) error {
+	return c.AddTrackersContext(context.Background(), hash, urls...)
+}
+
+// AddTrackersContext is like AddTrackers but uses ctx for the request.
+func (c *Client) AddTrackersContext(ctx context.Context, hash string, urls ...string) error {
+	return c.postAction(ctx, "torrents/addTrackers", Optional{
+		"hash": hash,
+		"urls": strings.Join(urls, "\n"),
+	})
+}
+
+// EditTracker replaces the tracker origURL of a torrent with newURL.
+// It fails with ErrConflict if newURL already exists or origURL was not found.
+func (c *Client) EditTracker(hash, origURL, newURL string) error {
+	return c.EditTrackerContext(context.Background(), hash, origURL, newURL)
+}
+
+// EditTrackerContext is like EditTracker but uses ctx for the request.
+func (c *Client) EditTrackerContext(ctx context.Context, hash, origURL, newURL string) error {
+	return c.postAction(ctx, "torrents/editTracker", Optional{
+		"hash":    hash,
+		"origUrl": origURL,
+		"newUrl":  newURL,
+	})
+}
+
+// RemoveTrackers removes trackers from a torrent.
+func (c *Client) RemoveTrackers(hash string, urls ...string) error {
+	return c.RemoveTrackersContext(context.Background(), hash, urls...)
+}
+
+// RemoveTrackersContext is like RemoveTrackers but uses ctx for the request.
+func (c *Client) RemoveTrackersContext(ctx context.Context, hash string, urls ...string) error {
+	return c.postAction(ctx, "torrents/removeTrackers", Optional{
+		"hash": hash,
+		"urls": strings.Join(urls, "|"),
+	})
+}
+
+// ReplaceTrackerPrefix replaces the prefix oldPrefix of every tracker url
+// of every torrent with newPrefix, e.g. to rotate the passkey of a private
+// tracker. It returns the number of edited trackers, the edits which failed
+// are reported together in the returned error.
+func (c *Client) ReplaceTrackerPrefix(oldPrefix, newPrefix string) (int, error) {
+	return c.ReplaceTrackerPrefixContext(context.Background(), oldPrefix, newPrefix)
+}
+
+// ReplaceTrackerPrefixContext is like ReplaceTrackerPrefix but uses ctx for the requests.
+func (c *Client) ReplaceTrackerPrefixContext(ctx context.Context, oldPrefix, newPrefix string) (int, error) {
+	trackers, err := c.trackerTorrents(ctx, oldPrefix)
+	if err != nil {
+		return 0, err
+	}
+	urls := make([]string, 0, len(trackers))
+	for u := range trackers {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	var (
+		n    int
+		errs []error
+	)
+	for _, u := range urls {
+		nu := newPrefix + strings.TrimPrefix(u, oldPrefix)
+		for _, h := range trackers[u] {
+			if err := ctx.Err(); err != nil {
+				return n, errors.Join(append(errs, err)...)
+			}
+			if err := c.EditTrackerContext(ctx, h, u, nu); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			n++
+		}
+	}
+	return n, errors.Join(errs...)
+}
+
+// trackerTorrents returns the hashes of the torrents using each tracker whose url
+// starts with prefix, read from `torrents/trackers` of every torrent.
+// The trackers of `sync/maindata` are not used, as a request with rid 0 would
+// reset the snapshot of the session shared with GetMainData and Watch.
+func (c *Client) trackerTorrents(ctx context.Context, prefix string) (map[string][]string, error) {
+	m := make(map[string][]string)
+	ts, err := c.TorrentListContext(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range ts {
+		trackers, err := c.GetTorrentTrackersContext(ctx, t.Hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, tr := range trackers {
+			if !tr.IsSpecial() && strings.HasPrefix(tr.URL, prefix) {
+				m[tr.URL] = append(m[tr.URL], t.Hash)
+			}
+		}
+	}
+	return m, nil
+}
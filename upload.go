@@ -0,0 +1,205 @@
+package qbt_apiv2
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/NullpointerW/go-qbittorrent-apiv2/metainfo"
+)
+
+// TorrentUpload is a .torrent file sent to `torrents/add`.
+// It is read again when a request is replayed after re-authentication.
+type TorrentUpload struct {
+	// Name is the file name sent to the server.
+	Name string
+	open func() (r io.Reader, size int64, close func() error, err error)
+}
+
+// UploadFile uploads the .torrent file at path.
+func UploadFile(path string) TorrentUpload {
+	return TorrentUpload{
+		Name: filepath.Base(path),
+		open: func() (io.Reader, int64, func() error, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, 0, nil, fmt.Errorf("error opening file: %w", err)
+			}
+			st, err := f.Stat()
+			if err != nil {
+				f.Close()
+				return nil, 0, nil, fmt.Errorf("error opening file: %w", err)
+			}
+			return f, st.Size(), f.Close, nil
+		},
+	}
+}
+
+// UploadBytes uploads the content of a .torrent file named name.
+func UploadBytes(name string, b []byte) TorrentUpload {
+	return TorrentUpload{
+		Name: name,
+		open: func() (io.Reader, int64, func() error, error) {
+			return bytes.NewReader(b), int64(len(b)), nopClose, nil
+		},
+	}
+}
+
+// UploadMetaInfo uploads the .torrent file mi was parsed from,
+// or the encoding of mi if it was built by hand, see MetaInfo.Bytes.
+func UploadMetaInfo(mi *metainfo.MetaInfo) TorrentUpload {
+	return TorrentUpload{
+		Name: mi.Info.Name + ".torrent",
+		open: func() (io.Reader, int64, func() error, error) {
+			b, err := mi.Bytes()
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			return bytes.NewReader(b), int64(len(b)), nopClose, nil
+		},
+	}
+}
+
+// UploadReader uploads the content of a .torrent file named name read from r.
+// If r is an io.Seeker it is streamed from its current offset and rewound to
+// it when the request is replayed, so the upload must not be used by concurrent
+// requests. Otherwise r is read into memory once, on first use, and the upload
+// may be reused freely.
+func UploadReader(name string, r io.Reader) TorrentUpload {
+	if s, ok := r.(io.ReadSeeker); ok {
+		start := int64(-1)
+		return TorrentUpload{
+			Name: name,
+			open: func() (io.Reader, int64, func() error, error) {
+				var err error
+				if start < 0 {
+					if start, err = s.Seek(0, io.SeekCurrent); err != nil {
+						return nil, 0, nil, err
+					}
+				}
+				end, err := s.Seek(0, io.SeekEnd)
+				if err != nil {
+					return nil, 0, nil, err
+				}
+				if _, err = s.Seek(start, io.SeekStart); err != nil {
+					return nil, 0, nil, err
+				}
+				return s, end - start, nopClose, nil
+			},
+		}
+	}
+	var (
+		once    sync.Once
+		b       []byte
+		readErr error
+	)
+	return TorrentUpload{
+		Name: name,
+		open: func() (io.Reader, int64, func() error, error) {
+			// a failed read is not retried, r may have been partly consumed
+			once.Do(func() {
+				b, readErr = io.ReadAll(r)
+			})
+			if readErr != nil {
+				return nil, 0, nil, readErr
+			}
+			return bytes.NewReader(b), int64(len(b)), nopClose, nil
+		},
+	}
+}
+
+func nopClose() error { return nil }
+
+// sizedReader is a request body of a known length, which http.NewRequest
+// only knows for in-memory readers.
+type sizedReader struct {
+	*io.PipeReader
+	size int64
+}
+
+// multipartStream returns a bodyFunc encoding opts and files as multipart/form-data
+// while the request is sent, the files are opened before the request starts.
+func multipartStream(opts Optional, files []TorrentUpload) bodyFunc {
+	fields := opts.StringField()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return func() (io.Reader, string, error) {
+		readers := make([]io.Reader, len(files))
+		closers := make([]func() error, 0, len(files))
+		closeAll := func() {
+			for _, cl := range closers {
+				cl()
+			}
+		}
+		var size int64
+		for i, f := range files {
+			r, n, cl, err := f.open()
+			if err != nil {
+				closeAll()
+				return nil, "", err
+			}
+			readers[i], size = r, size+n
+			closers = append(closers, cl)
+		}
+
+		// the length of the multipart framing does not depend on the content
+		// of the files, so it is measured by encoding them empty
+		var cw countWriter
+		framing := multipart.NewWriter(&cw)
+		if err := writeMultipart(framing, keys, fields, files, nil); err != nil {
+			closeAll()
+			return nil, "", err
+		}
+
+		pr, pw := io.Pipe()
+		mw := multipart.NewWriter(pw)
+		if err := mw.SetBoundary(framing.Boundary()); err != nil {
+			closeAll()
+			return nil, "", err
+		}
+		go func() {
+			defer closeAll()
+			pw.CloseWithError(writeMultipart(mw, keys, fields, files, readers))
+		}()
+		return &sizedReader{pr, int64(cw) + size}, mw.FormDataContentType(), nil
+	}
+}
+
+// writeMultipart writes the fields and files to w and closes it,
+// the files are left empty if readers is nil.
+func writeMultipart(w *multipart.Writer, keys []string, fields map[string]string, files []TorrentUpload, readers []io.Reader) error {
+	for _, k := range keys {
+		if err := w.WriteField(k, fields[k]); err != nil {
+			return err
+		}
+	}
+	for i, f := range files {
+		part, err := w.CreateFormFile("torrents", f.Name)
+		if err != nil {
+			return fmt.Errorf("error adding file: %w", err)
+		}
+		if readers == nil {
+			continue
+		}
+		if _, err = io.Copy(part, readers[i]); err != nil {
+			return fmt.Errorf("error copying file %s: %w", f.Name, err)
+		}
+	}
+	return w.Close()
+}
+
+type countWriter int64
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	*w += countWriter(len(p))
+	return len(p), nil
+}
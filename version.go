@@ -0,0 +1,92 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// apiVersion is a web API version as returned by `app/webapiVersion`, e.g. "2.11.0".
+type apiVersion struct {
+	major, minor, patch int
+}
+
+// Web API versions which introduced or renamed endpoints and parameters.
+var (
+	// qBittorrent v5.0.0, `torrents/pause` and `torrents/resume` are renamed to
+	// `torrents/stop` and `torrents/start`, `paused` of `torrents/add` to `stopped`
+	apiStopStart = apiVersion{2, 11, 0}
+	// qBittorrent v5.0.0, `torrents/addWebSeeds`, `torrents/editWebSeed` and `torrents/removeWebSeeds`
+	apiWebSeeds = apiVersion{2, 10, 3}
+	// qBittorrent v4.3.2, `root_folder` of `torrents/add` is replaced by `contentLayout`
+	apiContentLayout = apiVersion{2, 7, 0}
+	// qBittorrent v4.5.0, `torrents/export`
+	apiExport = apiVersion{2, 8, 14}
+	// qBittorrent v4.6.0, `inactiveSeedingTimeLimit` of `torrents/setShareLimits`
+	apiInactiveSeeding = apiVersion{2, 9, 3}
+	// qBittorrent v4.4.0, `torrents/setSavePath` and `torrents/setDownloadPath`
+	apiSavePath = apiVersion{2, 8, 4}
+	// qBittorrent v5.0.0, `torrents/setComment`
+	apiComment = apiVersion{2, 11, 0}
+)
+
+func parseAPIVersion(s string) (apiVersion, error) {
+	var v apiVersion
+	s = strings.TrimSpace(s)
+	n, _ := fmt.Sscanf(s, "%d.%d.%d", &v.major, &v.minor, &v.patch)
+	if n < 2 {
+		return apiVersion{}, fmt.Errorf("invalid web API version %q", s)
+	}
+	return v, nil
+}
+
+func (v apiVersion) atLeast(o apiVersion) bool {
+	if v.major != o.major {
+		return v.major > o.major
+	}
+	if v.minor != o.minor {
+		return v.minor > o.minor
+	}
+	return v.patch >= o.patch
+}
+
+func (v apiVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// apiVersion returns the web API version of the server,
+// it is cached until the next login.
+func (c *Client) apiVersion(ctx context.Context) (apiVersion, error) {
+	c.verMu.Lock()
+	cached := c.apiVer
+	c.verMu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+	// verMu is not held during the request, as it may login again
+	s, err := c.GetApiVersionContext(ctx)
+	if err != nil {
+		return apiVersion{}, err
+	}
+	v, err := parseAPIVersion(s)
+	if err != nil {
+		return apiVersion{}, err
+	}
+	c.verMu.Lock()
+	c.apiVer = &v
+	c.verMu.Unlock()
+	return v, nil
+}
+
+// requireAPI returns an error matching ErrNotSupported
+// if the web API of the server is older than since.
+func (c *Client) requireAPI(ctx context.Context, endpoint string, since apiVersion) error {
+	v, err := c.apiVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if !v.atLeast(since) {
+		return fmt.Errorf("%w: %s requires web API v%s, the server has v%s", ErrNotSupported, endpoint, since, v)
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// EventType identifies the change reported by an Event.
+type EventType int
+
+const (
+	EventError EventType = iota
+	EventTorrentAdded
+	EventTorrentRemoved
+	EventTorrentStateChanged
+	EventTorrentCompleted
+	EventTorrentProgressChanged
+	EventCategoryCreated
+	EventCategoryRemoved
+	EventTagCreated
+	EventTagRemoved
+	EventTrackersChanged
+	EventConnectionStatusChanged
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventError:
+		return "Error"
+	case EventTorrentAdded:
+		return "TorrentAdded"
+	case EventTorrentRemoved:
+		return "TorrentRemoved"
+	case EventTorrentStateChanged:
+		return "TorrentStateChanged"
+	case EventTorrentCompleted:
+		return "TorrentCompleted"
+	case EventTorrentProgressChanged:
+		return "TorrentProgressChanged"
+	case EventCategoryCreated:
+		return "CategoryCreated"
+	case EventCategoryRemoved:
+		return "CategoryRemoved"
+	case EventTagCreated:
+		return "TagCreated"
+	case EventTagRemoved:
+		return "TagRemoved"
+	case EventTrackersChanged:
+		return "TrackersChanged"
+	case EventConnectionStatusChanged:
+		return "ConnectionStatusChanged"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a change observed by Watch.
+// Only the fields related to its Type are set.
+type Event struct {
+	Type EventType
+
+	// Hash, Torrent and Prev are set for torrent events.
+	// Torrent is the current state, or the last known state when removed.
+	// Prev is the state before the change.
+	Hash    string
+	Torrent Torrent
+	Prev    Torrent
+
+	// Name is the name of the category or tag.
+	Name     string
+	Category Categories
+
+	// Tracker is the tracker url and Hashes the torrents using it,
+	// Hashes is empty when the tracker is removed.
+	Tracker string
+	Hashes  []string
+
+	// ConnectionStatus and PrevConnectionStatus are set for EventConnectionStatusChanged.
+	ConnectionStatus     string
+	PrevConnectionStatus string
+
+	// Err is set for EventError, Watch keeps polling after it.
+	Err error
+}
+
+const (
+	// defaultRefreshInterval is used until the server reports `refresh_interval`
+	defaultRefreshInterval = 1500 * time.Millisecond
+	maxWatchBackoff        = 30 * time.Second
+)
+
+// Watch polls `sync/maindata` at the `refresh_interval` of the server and emits
+// the changes as events on the returned channel. The first poll reports the
+// current state, i.e. an EventTorrentAdded for every torrent and so on.
+// Every poll diffs the whole state against the previous one. Failed polls are
+// reported as EventError and retried with an exponential backoff.
+// The channel is closed when ctx is done.
+//
+// The server keeps a single `sync/maindata` snapshot per session, so Watch
+// shares the `rid` and the state of GetMainData and GetMainDataFull, and they
+// can run together on the same Client without forcing full updates.
+func (c *Client) Watch(ctx context.Context) <-chan Event {
+	ch := make(chan Event, 64)
+	go c.watch(ctx, ch)
+	return ch
+}
+
+func (c *Client) watch(ctx context.Context, ch chan<- Event) {
+	defer close(ch)
+	var (
+		state   = newMainData()
+		backoff time.Duration
+	)
+	for {
+		interval := defaultRefreshInterval
+		if ri := state.ServerState.RefreshInterval; ri > 0 {
+			interval = time.Duration(ri) * time.Millisecond
+		}
+		cur, err := c.GetMainDataFullContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			backoff = nextBackoff(backoff, interval)
+			if !sendEvent(ctx, ch, Event{Type: EventError, Err: err}) || !sleep(ctx, backoff) {
+				return
+			}
+			continue
+		}
+		backoff = 0
+		// the updates may have been consumed by GetMainData in between,
+		// so every torrent is compared
+		events := diffMainData(state, &cur, unionKeys(state.Torrents, cur.Torrents))
+		state = &cur
+		for _, e := range events {
+			if !sendEvent(ctx, ch, e) {
+				return
+			}
+		}
+		if !sleep(ctx, interval) {
+			return
+		}
+	}
+}
+
+func diffMainData(old, cur *MainData, hashes []string) []Event {
+	var events []Event
+	if o, n := old.ServerState.ConnectionStatus, cur.ServerState.ConnectionStatus; o != n {
+		events = append(events, Event{
+			Type:                 EventConnectionStatusChanged,
+			ConnectionStatus:     n,
+			PrevConnectionStatus: o,
+		})
+	}
+	for _, k := range unionKeys(old.Categories, cur.Categories) {
+		o, hadO := old.Categories[k]
+		n, hasN := cur.Categories[k]
+		switch {
+		case !hadO && hasN:
+			events = append(events, Event{Type: EventCategoryCreated, Name: k, Category: n})
+		case hadO && !hasN:
+			events = append(events, Event{Type: EventCategoryRemoved, Name: k, Category: o})
+		}
+	}
+	for _, t := range cur.Tags {
+		if !containsStr(old.Tags, t) {
+			events = append(events, Event{Type: EventTagCreated, Name: t})
+		}
+	}
+	for _, t := range old.Tags {
+		if !containsStr(cur.Tags, t) {
+			events = append(events, Event{Type: EventTagRemoved, Name: t})
+		}
+	}
+	for _, k := range unionKeys(old.Trackers, cur.Trackers) {
+		if !sameSet(old.Trackers[k], cur.Trackers[k]) {
+			events = append(events, Event{Type: EventTrackersChanged, Tracker: k, Hashes: cur.Trackers[k]})
+		}
+	}
+	for _, h := range hashes {
+		o, hadO := old.Torrents[h]
+		n, hasN := cur.Torrents[h]
+		switch {
+		case !hadO && hasN:
+			events = append(events, Event{Type: EventTorrentAdded, Hash: h, Torrent: n})
+		case hadO && !hasN:
+			events = append(events, Event{Type: EventTorrentRemoved, Hash: h, Torrent: o, Prev: o})
+		case hadO && hasN:
+			if o.State != n.State {
+				events = append(events, Event{Type: EventTorrentStateChanged, Hash: h, Torrent: n, Prev: o})
+			}
+			if o.Progress != n.Progress {
+				events = append(events, Event{Type: EventTorrentProgressChanged, Hash: h, Torrent: n, Prev: o})
+				if o.Progress < 1 && n.Progress >= 1 {
+					events = append(events, Event{Type: EventTorrentCompleted, Hash: h, Torrent: n, Prev: o})
+				}
+			}
+		}
+	}
+	return events
+}
+
+// unionKeys returns the sorted keys of a and b.
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, v := range a {
+		if !containsStr(b, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextBackoff doubles backoff, starting at interval and capped at maxWatchBackoff.
+func nextBackoff(backoff, interval time.Duration) time.Duration {
+	if backoff *= 2; backoff < interval {
+		return interval
+	}
+	if backoff > maxWatchBackoff {
+		return maxWatchBackoff
+	}
+	return backoff
+}
+
+func sendEvent[E any](ctx context.Context, ch chan<- E, e E) bool {
+	select {
+	case ch <- e:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
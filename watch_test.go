@@ -0,0 +1,167 @@
+package qbt_apiv2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	payloads := []string{
+		`{"rid":1,"full_update":true,
+			"server_state":{"connection_status":"connected","refresh_interval":10},
+			"torrents":{"aaa":{"name":"a","state":"downloading","progress":0.5}},
+			"categories":{"os":{"name":"os","savePath":"/os"}},
+			"tags":["linux"],
+			"trackers":{"http://t/announce":["aaa"]}}`,
+		`{"rid":2,"torrents":{"aaa":{"progress":1,"state":"uploading"},"bbb":{"name":"b","state":"metaDL"}},
+			"trackers":{"http://t/announce":["aaa","bbb"]},"tags":["new"]}`,
+		// a failed poll in between
+		``,
+		`{"rid":3,"torrents_removed":["bbb"],"categories_removed":["os"],"tags_removed":["linux"],
+			"server_state":{"connection_status":"firewalled"}}`,
+		// the server lost its state, e.g. it was restarted
+		`{"rid":1,"full_update":true,"server_state":{"connection_status":"firewalled","refresh_interval":10},
+			"torrents":{"ccc":{"name":"c","state":"pausedDL"}},"tags":["new"]}`,
+	}
+	var (
+		mu sync.Mutex
+		n  int
+	)
+	srv := newFakeServer(t)
+	srv.handle("sync/maindata", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if n >= len(payloads) {
+			w.Write([]byte(`{"rid":` + strconv.Itoa(n) + `}`))
+			return
+		}
+		p := payloads[n]
+		n++
+		if p == "" {
+			http.Error(w, "oops", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(p))
+	})
+	cli := srv.client(t)
+
+	want := []struct {
+		typ  EventType
+		name string
+	}{
+		{EventConnectionStatusChanged, "connected"},
+		{EventCategoryCreated, "os"},
+		{EventTagCreated, "linux"},
+		{EventTrackersChanged, "http://t/announce"},
+		{EventTorrentAdded, "aaa"},
+
+		{EventTagCreated, "new"},
+		{EventTrackersChanged, "http://t/announce"},
+		{EventTorrentStateChanged, "aaa"},
+		{EventTorrentProgressChanged, "aaa"},
+		{EventTorrentCompleted, "aaa"},
+		{EventTorrentAdded, "bbb"},
+
+		{EventError, ""},
+
+		{EventConnectionStatusChanged, "firewalled"},
+		{EventCategoryRemoved, "os"},
+		{EventTagRemoved, "linux"},
+		{EventTorrentRemoved, "bbb"},
+
+		{EventTrackersChanged, "http://t/announce"},
+		{EventTorrentRemoved, "aaa"},
+		{EventTorrentAdded, "ccc"},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := cli.Watch(ctx)
+	for i, w := range want {
+		e, ok := <-events
+		if !ok {
+			t.Fatalf("channel closed after %d events", i)
+		}
+		var name string
+		switch e.Type {
+		case EventConnectionStatusChanged:
+			name = e.ConnectionStatus
+		case EventCategoryCreated, EventCategoryRemoved, EventTagCreated, EventTagRemoved:
+			name = e.Name
+		case EventTrackersChanged:
+			name = e.Tracker
+		case EventError:
+		default:
+			name = e.Hash
+		}
+		if e.Type != w.typ || name != w.name {
+			t.Errorf("event %d = %v %q, want %v %q", i, e.Type, name, w.typ, w.name)
+		}
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchSharesSession(t *testing.T) {
+	// the server keeps a single snapshot per session,
+	// a rid other than the last one issued gets a full update
+	var (
+		mu       sync.Mutex
+		last     int
+		full     int
+		progress = "0.5"
+	)
+	srv := newFakeServer(t)
+	srv.handle("sync/maindata", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		rid, _ := strconv.Atoi(r.FormValue("rid"))
+		last++
+		if rid == 0 || rid != last-1 {
+			full++
+			fmt.Fprintf(w, `{"rid":%d,"full_update":true,"server_state":{"refresh_interval":10},
+				"torrents":{"aaa":{"name":"a","progress":%s}}}`, last, progress)
+			return
+		}
+		fmt.Fprintf(w, `{"rid":%d,"torrents":{"aaa":{"progress":%s}}}`, last, progress)
+	})
+	cli := srv.client(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := cli.Watch(ctx)
+	if e := <-events; e.Type != EventTorrentAdded || e.Hash != "aaa" {
+		t.Fatalf("first event = %v %q", e.Type, e.Hash)
+	}
+	mu.Lock()
+	progress = "1"
+	mu.Unlock()
+	s, err := cli.GetMainData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.FullUpdate {
+		t.Error("GetMainData got a full update while Watch is running")
+	}
+	// the change consumed by GetMainData is still reported
+	if e := <-events; e.Type != EventTorrentProgressChanged || e.Torrent.Progress != 1 {
+		t.Errorf("second event = %v %+v", e.Type, e.Torrent)
+	}
+	if e := <-events; e.Type != EventTorrentCompleted {
+		t.Errorf("third event = %v", e.Type)
+	}
+	cancel()
+	for range events {
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if full != 1 {
+		t.Errorf("%d full updates in %d polls, want 1", full, last)
+	}
+}